@@ -0,0 +1,79 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/andreshungbz/lab4-database-crud/internal/data"
+	"github.com/andreshungbz/lab4-database-crud/internal/validator"
+)
+
+// updateAccountPasswordHandler reads the authenticated employee's current and
+// new passwords as JSON input and replaces the stored hash. All of the
+// employee's authentication tokens are revoked so they must authenticate again.
+func (app *application) updateAccountPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	employee := app.contextGetEmployee(r)
+
+	// Read JSON input
+
+	var input struct {
+		CurrentPassword string `json:"current_password"`
+		NewPassword     string `json:"new_password"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	// validate
+	v := validator.New()
+	v.Check(input.CurrentPassword != "", "current_password", "must be provided")
+	data.ValidatePasswordStrength(v, "new_password", input.NewPassword, employee.WorkEmail)
+	v.Check(input.CurrentPassword != input.NewPassword, "new_password", "must be different from the current password")
+
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	// ensure the current password is correct
+	match, _, err := employee.Password.Matches(input.CurrentPassword)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !match {
+		v.AddError("current_password", "is incorrect")
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	// hash and store the new password
+	err = employee.Password.Set(input.NewPassword)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.models.Employee.UpdatePassword(r.Context(), employee)
+	if err != nil {
+		app.dataErrorResponse(w, r, err)
+		return
+	}
+
+	// revoke existing sessions
+	err = app.models.Token.DeleteAllForEmployee(r.Context(), data.ScopeAuthentication, employee.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// return JSON response indicating success
+	env := envelope{"message": "password successfully changed, please authenticate again"}
+	err = app.writeJSON(w, http.StatusOK, env, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
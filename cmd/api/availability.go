@@ -0,0 +1,47 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/andreshungbz/lab4-database-crud/internal/data"
+	"github.com/andreshungbz/lab4-database-crud/internal/validator"
+)
+
+// showAvailabilityHandler reads a hotel's id along with a date range and number
+// of guests, and returns JSON of the available rooms per room type and the
+// price of the stay.
+func (app *application) showAvailabilityHandler(w http.ResponseWriter, r *http.Request) {
+	// read id parameter
+	hotelID, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	// read the search URL keys
+	v := validator.New()
+	qs := r.URL.Query()
+
+	checkin := app.readDate(qs, "checkin", v)
+	checkout := app.readDate(qs, "checkout", v)
+	guests := app.readInt(qs, "guests", 1, v)
+
+	// validate
+	if data.ValidateAvailabilitySearch(v, checkin, checkout, guests); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	// retrieve availability from the database
+	availability, err := app.models.Availability.Search(r.Context(), hotelID, checkin, checkout, guests)
+	if err != nil {
+		app.dataErrorResponse(w, r, err)
+		return
+	}
+
+	// return JSON response of the availability per room type
+	err = app.writeJSON(w, http.StatusOK, envelope{"availability": availability}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// sources of a configuration value, from lowest to highest precedence
+const (
+	sourceDefault = "default"
+	sourceFile    = "file"
+	sourceEnv     = "env"
+	sourceFlag    = "flag"
+	sourceDBParts = "db parts"
+)
+
+// redacted replaces secrets when the configuration is printed, as in
+// url.URL.Redacted.
+const redacted = "xxxxx"
+
+// loadConfig defines the configuration flags on fs and fills a config from, in
+// increasing precedence, the flag defaults, a YAML or TOML file, environment
+// variables, and the flags in args. The environment variable of a setting is
+// named by envName, and its file key is the flag name, which may be nested in
+// sections named after its leading words (e.g. query-timeout under db). Flags
+// defined on fs beforehand are only read from args. The returned map records
+// the source of each setting.
+func loadConfig(fs *flag.FlagSet, args []string, lookupEnv func(string) (string, bool)) (config, map[string]string, error) {
+	var cfg config
+
+	// flags that are not settings
+	before := make(map[string]bool)
+	fs.VisitAll(func(f *flag.Flag) {
+		before[f.Name] = true
+	})
+
+	fs.StringVar(&cfg.file, "config-file", "", "Path of a YAML or TOML configuration file")
+
+	fs.IntVar(&cfg.port, "port", 4000, "API server port")
+	fs.StringVar(&cfg.env, "env", "development", "Environment (development|staging|production)")
+
+	fs.StringVar(&cfg.db.dsn, "db-dsn", "", "PostgreSQL DSN (overrides the other db connection settings)")
+	fs.StringVar(&cfg.db.host, "db-host", "", "PostgreSQL host")
+	fs.IntVar(&cfg.db.port, "db-port", 0, "PostgreSQL port")
+	fs.StringVar(&cfg.db.user, "db-user", "", "PostgreSQL user")
+	fs.StringVar(&cfg.db.password, "db-password", "", "PostgreSQL password")
+	fs.StringVar(&cfg.db.name, "db-name", "", "PostgreSQL database name")
+	fs.StringVar(&cfg.db.sslMode, "db-sslmode", "", "PostgreSQL SSL mode")
+	fs.DurationVar(&cfg.db.queryTimeout, "db-query-timeout", 3*time.Second, "PostgreSQL query timeout")
+	fs.BoolVar(&cfg.db.autoMigrate, "db-auto-migrate", false, "Apply pending migrations on startup")
+
+	fs.IntVar(&cfg.db.maxOpenConns, "db-max-open-conns", 25, "PostgreSQL max open connections (0 for unlimited)")
+	fs.IntVar(&cfg.db.maxIdleConns, "db-max-idle-conns", 25, "PostgreSQL max idle connections")
+	fs.DurationVar(&cfg.db.maxIdleTime, "db-max-idle-time", 15*time.Minute, "PostgreSQL max connection idle time (0 for unlimited)")
+	fs.DurationVar(&cfg.db.maxLifetime, "db-max-lifetime", time.Hour, "PostgreSQL max connection lifetime (0 for unlimited)")
+	fs.DurationVar(&cfg.db.connectTimeout, "db-connect-timeout", 30*time.Second, "Time to wait for PostgreSQL to accept connections on startup")
+
+	fs.IntVar(&cfg.refund.FreeDays, "refund-free-days", 7, "Days before check-in that a reservation can be canceled for a full refund")
+	fs.Float64Var(&cfg.refund.LatePercentage, "refund-late-percentage", 50, "Percentage of the payment refunded for later cancellations")
+
+	fs.StringVar(&cfg.timeZone, "time-zone", "America/Belize", "Time zone of the hotel (IANA name)")
+	fs.IntVar(&cfg.shifts.DayStart, "shift-day-start", 7, "Hour the housekeeping day shift starts, in the hotel's time zone")
+	fs.IntVar(&cfg.shifts.DayEnd, "shift-day-end", 19, "Hour the housekeeping day shift ends and the night shift starts")
+
+	fs.StringVar(&cfg.guests.duplicateEmail, "guest-duplicate-email", "warn", "Handling of new guests whose contact email belongs to another guest (off|warn|block)")
+
+	err := fs.Parse(args)
+	if err != nil {
+		return config{}, nil, err
+	}
+
+	sources := make(map[string]string)
+	fs.VisitAll(func(f *flag.Flag) {
+		if !before[f.Name] {
+			sources[f.Name] = sourceDefault
+		}
+	})
+	fs.Visit(func(f *flag.Flag) {
+		if !before[f.Name] {
+			sources[f.Name] = sourceFlag
+		}
+	})
+
+	// set a setting unless it was passed as a flag
+	set := func(name, value, source string) error {
+		if sources[name] == sourceFlag {
+			return nil
+		}
+		err := fs.Set(name, value)
+		if err != nil {
+			return err
+		}
+		sources[name] = source
+		return nil
+	}
+
+	// the file path itself can only come from the environment or a flag
+	if path, ok := lookupEnv(envName("config-file")); ok {
+		err = set("config-file", path, sourceEnv)
+		if err != nil {
+			return config{}, nil, fmt.Errorf("%s: %w", envName("config-file"), err)
+		}
+	}
+
+	if cfg.file != "" {
+		settings, err := readConfigFile(cfg.file)
+		if err != nil {
+			return config{}, nil, err
+		}
+
+		for name, value := range settings {
+			if _, ok := sources[name]; !ok || name == "config-file" {
+				return config{}, nil, fmt.Errorf("%s: unknown setting %q", cfg.file, name)
+			}
+			err = set(name, value, sourceFile)
+			if err != nil {
+				return config{}, nil, fmt.Errorf("%s: %s: %w", cfg.file, name, err)
+			}
+		}
+	}
+
+	for name := range sources {
+		value, ok := lookupEnv(envName(name))
+		if !ok || name == "config-file" {
+			continue
+		}
+		err = set(name, value, sourceEnv)
+		if err != nil {
+			return config{}, nil, fmt.Errorf("%s: %w", envName(name), err)
+		}
+	}
+
+	// assemble the DSN from its parts if it was not given whole
+	if cfg.db.dsn == "" && cfg.db.host != "" {
+		cfg.db.dsn = cfg.db.buildDSN()
+		sources["db-dsn"] = sourceDBParts
+	}
+
+	return cfg, sources, nil
+}
+
+// envName returns the environment variable of a setting, its flag name in upper
+// case with underscores. Database settings keep the conventional DB_ names
+// (e.g. DB_QUERY_TIMEOUT), while the others are prefixed with HOTEL_ (e.g.
+// HOTEL_PORT) so that variables like PORT and ENV set by the platform or shell
+// are not mistaken for them.
+func envName(name string) string {
+	name = strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+	if strings.HasPrefix(name, "DB_") {
+		return name
+	}
+
+	return "HOTEL_" + name
+}
+
+// readConfigFile reads a YAML or TOML file, chosen by its extension, into
+// settings keyed by flag name.
+func readConfigFile(path string) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var tree map[string]any
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &tree)
+	case ".toml":
+		err = toml.Unmarshal(content, &tree)
+	default:
+		return nil, fmt.Errorf("%s: configuration files must be .yaml, .yml, or .toml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	settings := make(map[string]string)
+	err = flattenSettings(settings, "", tree)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return settings, nil
+}
+
+// flattenSettings adds the values of a file's nested sections to settings,
+// joining the keys with dashes. Underscores in keys are treated as dashes.
+func flattenSettings(settings map[string]string, prefix string, tree map[string]any) error {
+	for key, value := range tree {
+		name := strings.ReplaceAll(strings.ToLower(key), "_", "-")
+		if prefix != "" {
+			name = prefix + "-" + name
+		}
+
+		switch value := value.(type) {
+		case map[string]any:
+			err := flattenSettings(settings, name, value)
+			if err != nil {
+				return err
+			}
+		case string, bool, int, int64, uint64, float64:
+			settings[name] = fmt.Sprint(value)
+		default:
+			return fmt.Errorf("%s: unsupported value %v", name, value)
+		}
+	}
+
+	return nil
+}
+
+// buildDSN returns a PostgreSQL URL from the connection settings.
+func (db dbConfig) buildDSN() string {
+	host := db.host
+	if db.port != 0 {
+		host = net.JoinHostPort(db.host, fmt.Sprint(db.port))
+	}
+
+	dsn := url.URL{
+		Scheme: "postgres",
+		Host:   host,
+		Path:   "/" + db.name,
+	}
+
+	switch {
+	case db.user != "" && db.password != "":
+		dsn.User = url.UserPassword(db.user, db.password)
+	case db.user != "":
+		dsn.User = url.User(db.user)
+	}
+
+	if db.sslMode != "" {
+		dsn.RawQuery = url.Values{"sslmode": {db.sslMode}}.Encode()
+	}
+
+	return dsn.String()
+}
+
+// dsnPasswordRX matches the password in a key/value DSN.
+var dsnPasswordRX = regexp.MustCompile(`(password\s*=\s*)('(?:[^'\\]|\\.)*'|\S+)`)
+
+// redactDSN hides the password of a URL or key/value DSN.
+func redactDSN(dsn string) string {
+	if u, err := url.Parse(dsn); err == nil && u.Scheme != "" {
+		return u.Redacted()
+	}
+
+	return dsnPasswordRX.ReplaceAllString(dsn, "${1}"+redacted)
+}
+
+// printConfig writes the settings with their values and sources, hiding the
+// database password.
+func printConfig(w io.Writer, fs *flag.FlagSet, sources map[string]string) {
+	fs.VisitAll(func(f *flag.Flag) {
+		source, ok := sources[f.Name]
+		if !ok {
+			return
+		}
+
+		value := f.Value.String()
+		switch {
+		case f.Name == "db-dsn":
+			value = redactDSN(value)
+		case f.Name == "db-password" && value != "":
+			value = redacted
+		}
+
+		fmt.Fprintf(w, "%s=%q\t# %s\n", f.Name, value, source)
+	})
+}
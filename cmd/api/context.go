@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/andreshungbz/lab4-database-crud/internal/data"
+)
+
+// contextKey is used for the keys of values stored in a request context so
+// that they do not collide with keys from other packages.
+type contextKey string
+
+const employeeContextKey = contextKey("employee")
+
+// contextSetEmployee returns a copy of the request with the Employee stored in
+// its context.
+func (app *application) contextSetEmployee(r *http.Request, employee *data.Employee) *http.Request {
+	ctx := context.WithValue(r.Context(), employeeContextKey, employee)
+	return r.WithContext(ctx)
+}
+
+// contextGetEmployee retrieves the Employee stored in the request context. It
+// is only called when the authenticate middleware has run, so a missing value
+// is a programmer error.
+func (app *application) contextGetEmployee(r *http.Request) *data.Employee {
+	employee, ok := r.Context().Value(employeeContextKey).(*data.Employee)
+	if !ok {
+		panic("missing employee value in request context")
+	}
+
+	return employee
+}
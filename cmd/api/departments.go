@@ -0,0 +1,42 @@
+package main
+
+import (
+	"net/http"
+)
+
+// listDepartmentsHandler returns JSON of all departments with their budgets and
+// payroll totals.
+func (app *application) listDepartmentsHandler(w http.ResponseWriter, r *http.Request) {
+	// retrieve records from the database
+	departments, err := app.models.Department.GetAll(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// return JSON response of the list of departments
+	err = app.writeJSON(w, http.StatusOK, envelope{"departments": departments}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// showDepartmentPayrollHandler reads a department's name and returns a JSON
+// response of its payroll broken down by hotel.
+func (app *application) showDepartmentPayrollHandler(w http.ResponseWriter, r *http.Request) {
+	// read name parameter
+	name := app.readDepartmentParam(r)
+
+	// retrieve payroll from database
+	payroll, err := app.models.Department.GetPayroll(r.Context(), name)
+	if err != nil {
+		app.dataErrorResponse(w, r, err)
+		return
+	}
+
+	// return JSON response of the payroll
+	err = app.writeJSON(w, http.StatusOK, envelope{"payroll": payroll}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
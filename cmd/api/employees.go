@@ -0,0 +1,370 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/andreshungbz/lab4-database-crud/internal/data"
+	"github.com/andreshungbz/lab4-database-crud/internal/validator"
+)
+
+// createEmployeeHandler reads JSON input and creates an employee along with
+// the subtype record of their role.
+func (app *application) createEmployeeHandler(w http.ResponseWriter, r *http.Request) {
+	// Read JSON input
+
+	var input struct {
+		HotelID    int64   `json:"hotel_id"`
+		Department string  `json:"department"`
+		ReportsTo  *int64  `json:"reports_to"`
+		Salary     float64 `json:"salary"`
+		SSN        string  `json:"ssn"`
+		WorkEmail  string  `json:"work_email"`
+		WorkPhone  string  `json:"work_phone"`
+		Password   string  `json:"password"`
+		Role       string  `json:"role"`
+		Shift      *string `json:"shift"`
+		HotelOwner bool    `json:"hotel_owner"`
+		Name       string  `json:"name"`
+		Gender     string  `json:"gender"`
+		Street     string  `json:"street"`
+		City       string  `json:"city"`
+		Country    string  `json:"country"`
+		// override the department budget check
+		BudgetOverride bool `json:"budget_override"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	employee := &data.Employee{
+		HotelID:    input.HotelID,
+		Department: input.Department,
+		ReportsTo:  input.ReportsTo,
+		Salary:     input.Salary,
+		SSN:        input.SSN,
+		WorkEmail:  input.WorkEmail,
+		WorkPhone:  input.WorkPhone,
+		Role:       input.Role,
+		Shift:      input.Shift,
+		HotelOwner: input.HotelOwner,
+		Name:       input.Name,
+		Gender:     input.Gender,
+		Street:     input.Street,
+		City:       input.City,
+		Country:    input.Country,
+	}
+
+	// validate
+	v := validator.New()
+	data.ValidateEmployee(v, employee)
+	data.ValidatePasswordStrength(v, "password", input.Password, input.WorkEmail)
+
+	err = app.checkPrivilegedFields(r, v, input.BudgetOverride, input.HotelOwner)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = employee.Password.Set(input.Password)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// insert into database
+	err = app.models.Employee.Insert(r.Context(), employee, input.BudgetOverride)
+	if err != nil {
+		app.employeeErrorResponse(w, r, v, err)
+		return
+	}
+
+	// return JSON response of newly created employee
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/employees/%d", employee.ID))
+
+	err = app.writeJSON(w, http.StatusCreated, envelope{"employee": employee}, headers)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// showEmployeeHandler reads an employee's id and returns a JSON response for
+// that employee.
+func (app *application) showEmployeeHandler(w http.ResponseWriter, r *http.Request) {
+	// read id parameter
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	// retrieve employee from database
+	employee, err := app.models.Employee.Get(r.Context(), id)
+	if err != nil {
+		app.dataErrorResponse(w, r, err)
+		return
+	}
+
+	// return JSON response of retrieved employee
+	err = app.writeJSON(w, http.StatusOK, envelope{"employee": employee}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// listEmployeesHandler returns JSON of employees. It can be filtered by hotel,
+// department, role, and status (employed by default).
+func (app *application) listEmployeesHandler(w http.ResponseWriter, r *http.Request) {
+	// read the filter URL keys
+	v := validator.New()
+	qs := r.URL.Query()
+
+	var ef data.EmployeeFilters
+	ef.HotelID = int64(app.readInt(qs, "hotel_id", 0, v))
+	ef.Department = app.readString(qs, "department", "")
+	ef.Role = app.readString(qs, "role", "")
+	ef.Status = app.readString(qs, "status", "employed")
+
+	// validate
+	if data.ValidateEmployeeFilters(v, ef); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	// retrieve records from the database
+	employees, err := app.models.Employee.GetAll(r.Context(), ef)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// return JSON response of the list of employees
+	err = app.writeJSON(w, http.StatusOK, envelope{"employees": employees}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// updateEmployeeHandler reads an employee's id and JSON input, and partially
+// updates the employee. An employee's role cannot be changed.
+func (app *application) updateEmployeeHandler(w http.ResponseWriter, r *http.Request) {
+	// read id parameter
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	// retrieve employee from database
+	employee, err := app.models.Employee.Get(r.Context(), id)
+	if err != nil {
+		app.dataErrorResponse(w, r, err)
+		return
+	}
+
+	// Read JSON input
+
+	var input struct {
+		HotelID    *int64          `json:"hotel_id"`
+		Department *string         `json:"department"`
+		ReportsTo  optional[int64] `json:"reports_to"`
+		Salary     *float64        `json:"salary"`
+		SSN        *string         `json:"ssn"`
+		WorkEmail  *string         `json:"work_email"`
+		WorkPhone  *string         `json:"work_phone"`
+		Shift      *string         `json:"shift"`
+		HotelOwner *bool           `json:"hotel_owner"`
+		Name       *string         `json:"name"`
+		Gender     *string         `json:"gender"`
+		Street     *string         `json:"street"`
+		City       *string         `json:"city"`
+		Country    *string         `json:"country"`
+		// override the department budget check
+		BudgetOverride bool `json:"budget_override"`
+	}
+
+	err = app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if input.HotelID != nil {
+		employee.HotelID = *input.HotelID
+	}
+	if input.Department != nil {
+		employee.Department = *input.Department
+	}
+	// a null reports_to removes the employee's supervisor
+	switch {
+	case input.ReportsTo.Null:
+		employee.ReportsTo = nil
+	case input.ReportsTo.Set:
+		employee.ReportsTo = &input.ReportsTo.Value
+	}
+	if input.Salary != nil {
+		employee.Salary = *input.Salary
+	}
+	if input.SSN != nil {
+		employee.SSN = *input.SSN
+	}
+	if input.WorkEmail != nil {
+		employee.WorkEmail = *input.WorkEmail
+	}
+	if input.WorkPhone != nil {
+		employee.WorkPhone = *input.WorkPhone
+	}
+	if input.Shift != nil {
+		employee.Shift = input.Shift
+	}
+	// only hotel owners can grant or revoke ownership
+	ownerChange := input.HotelOwner != nil && *input.HotelOwner != employee.HotelOwner
+	if input.HotelOwner != nil {
+		employee.HotelOwner = *input.HotelOwner
+	}
+	if input.Name != nil {
+		employee.Name = *input.Name
+	}
+	if input.Gender != nil {
+		employee.Gender = *input.Gender
+	}
+	if input.Street != nil {
+		employee.Street = *input.Street
+	}
+	if input.City != nil {
+		employee.City = *input.City
+	}
+	if input.Country != nil {
+		employee.Country = *input.Country
+	}
+
+	// validate
+	v := validator.New()
+	data.ValidateEmployee(v, employee)
+
+	err = app.checkPrivilegedFields(r, v, input.BudgetOverride, ownerChange)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	// update record in the database
+	err = app.models.Employee.Update(r.Context(), employee, input.BudgetOverride)
+	if err != nil {
+		app.employeeErrorResponse(w, r, v, err)
+		return
+	}
+
+	// return JSON response of updated employee
+	err = app.writeJSON(w, http.StatusOK, envelope{"employee": employee}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// terminateEmployeeHandler reads an employee's id and marks them as no longer
+// employed. The employee's records are kept.
+func (app *application) terminateEmployeeHandler(w http.ResponseWriter, r *http.Request) {
+	// read id parameter
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	// terminate the employee
+	err = app.models.Employee.Terminate(r.Context(), id)
+	if err != nil {
+		app.dataErrorResponse(w, r, err)
+		return
+	}
+
+	// return JSON response of successful termination
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "employee successfully terminated"}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// listEmployeeReportsHandler reads an employee's id and returns the tree of
+// employees that report to them.
+func (app *application) listEmployeeReportsHandler(w http.ResponseWriter, r *http.Request) {
+	// read id parameter
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	// retrieve reporting tree from database
+	reports, err := app.models.Employee.GetReports(r.Context(), id)
+	if err != nil {
+		app.dataErrorResponse(w, r, err)
+		return
+	}
+
+	// return JSON response of the reporting tree
+	err = app.writeJSON(w, http.StatusOK, envelope{"employee_id": id, "reports": reports}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// checkPrivilegedFields records validation errors for the fields that only some
+// employees may set: a department budget override is reserved for operations
+// managers, and making or unmaking a hotel owner is reserved for hotel owners.
+func (app *application) checkPrivilegedFields(r *http.Request, v *validator.Validator, budgetOverride, ownerChange bool) error {
+	if !budgetOverride && !ownerChange {
+		return nil
+	}
+
+	roles, err := app.models.Permission.GetRolesForEmployee(r.Context(), app.contextGetEmployee(r).ID)
+	if err != nil {
+		return err
+	}
+
+	if budgetOverride {
+		v.Check(slices.Contains(roles, data.RoleOperationsManager), "budget_override", "must only be set by an operations manager")
+	}
+	if ownerChange {
+		v.Check(slices.Contains(roles, data.RoleHotelOwner), "hotel_owner", "must only be changed by a hotel owner")
+	}
+
+	return nil
+}
+
+// employeeErrorResponse names the field responsible for a constraint violation
+// or reporting cycle of an employee, and reports any other error through
+// dataErrorResponse.
+func (app *application) employeeErrorResponse(w http.ResponseWriter, r *http.Request, v *validator.Validator, err error) {
+	field, ok := data.EmployeeConstraintField(err)
+
+	switch {
+	case errors.Is(err, data.ErrReportingCycle):
+		v.AddError("reports_to", "must not be an employee that reports to this employee")
+		app.failedValidationResponse(w, r, v.Errors)
+	case errors.Is(err, data.ErrDuplicateRecord) && ok:
+		app.codedErrorResponse(w, r, http.StatusConflict, "duplicate-record", fmt.Sprintf("an employee with this %s already exists", field))
+	case errors.Is(err, data.ErrInvalidReference) && ok:
+		v.AddError(field, "must refer to an existing record")
+		app.failedValidationResponse(w, r, v.Errors)
+	default:
+		app.dataErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/andreshungbz/lab4-database-crud/internal/data"
+)
+
+func TestCreateEmployeeHotelOwner(t *testing.T) {
+	// the name is missing so that the request always stops at validation
+	const body = `{
+		"hotel_id": 1,
+		"department": "Front Office",
+		"salary": 1000,
+		"ssn": "123-45-6789",
+		"work_email": "new.owner@example.com",
+		"work_phone": "501-111-1234",
+		"password": "Correct-Horse-9",
+		"role": "operations_manager",
+		"hotel_owner": true
+	}`
+
+	tests := []struct {
+		name      string
+		roles     []string
+		permitted bool
+	}{
+		{"operations manager", []string{data.RoleOperationsManager}, false},
+		{"hotel owner", []string{data.RoleOperationsManager, data.RoleHotelOwner}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication()
+			headers := authenticateAs(t, app, tt.roles...)
+
+			rr := send(app.routes(), http.MethodPost, "/v1/employees", body, headers)
+
+			if rr.Code != http.StatusUnprocessableEntity {
+				t.Fatalf("Expected status %d, got %d: %s", http.StatusUnprocessableEntity, rr.Code, rr.Body)
+			}
+
+			errs := decodeResponse(t, rr)["error"].(map[string]any)
+			if _, rejected := errs["hotel_owner"]; rejected == tt.permitted {
+				t.Errorf("Expected hotel_owner to be permitted %t, got errors %v", tt.permitted, errs)
+			}
+		})
+	}
+}
@@ -1,10 +1,19 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
+
+	"github.com/andreshungbz/lab4-database-crud/internal/data"
 )
 
+// statusClientClosedRequest is the nonstandard status code for a request whose
+// client disconnected before the response was sent.
+const statusClientClosedRequest = 499
+
 // logError writes server-side error messages. It records the error
 // and HTTP request method and URI.
 func (app *application) logError(r *http.Request, err error) {
@@ -22,15 +31,49 @@ func (app *application) errorResponse(w http.ResponseWriter, r *http.Request, st
 	}
 }
 
+// codedErrorResponse writes error messages to the client in JSON along with
+// a machine-readable error code.
+func (app *application) codedErrorResponse(w http.ResponseWriter, r *http.Request, status int, code string, message any) {
+	env := envelope{"error": message, "code": code}
+
+	err := app.writeJSON(w, status, env, nil)
+	if err != nil {
+		app.logError(r, err)
+		w.WriteHeader(500)
+	}
+}
+
 // serverErrorResponse sends a generic error message in JSON indicating
 // a problem with the server.
 func (app *application) serverErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, data.ErrQueryCanceled) {
+		app.queryCanceledResponse(w, r, err)
+		return
+	}
+
 	app.logError(r, err)
 
 	message := "The server encountered a problem and could not process your request"
 	app.errorResponse(w, r, http.StatusInternalServerError, message)
 }
 
+// queryCanceledResponse sends a 499 HTTP status code when a query was canceled
+// because the client disconnected, or a 503 HTTP status code when it timed out
+// or the server is shutting down.
+func (app *application) queryCanceledResponse(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(r.Context().Err(), context.Canceled) && !errors.Is(context.Cause(r.Context()), errServerShutdown) {
+		app.logger.Info("client closed request", "method", r.Method, "uri", r.URL.RequestURI())
+		app.errorResponse(w, r, statusClientClosedRequest, "the client closed the request before it completed")
+		return
+	}
+
+	app.logError(r, err)
+
+	w.Header().Set("Retry-After", "5")
+	message := "the server could not complete your request in time, please try again later"
+	app.codedErrorResponse(w, r, http.StatusServiceUnavailable, "query-canceled", message)
+}
+
 // notFound Response sends a 404 HTTP status code.
 func (app *application) notFoundResponse(w http.ResponseWriter, r *http.Request) {
 	message := "the requested resource could not be found"
@@ -59,3 +102,121 @@ func (app *application) editConflictResponse(w http.ResponseWriter, r *http.Requ
 	message := "unable to update the record due to an edit conflict, please try again"
 	app.errorResponse(w, r, http.StatusConflict, message)
 }
+
+// preconditionFailedResponse sends a 412 HTTP status code when the If-Match
+// header does not match the current version of a resource.
+func (app *application) preconditionFailedResponse(w http.ResponseWriter, r *http.Request) {
+	message := "the resource has been modified since it was last retrieved, please retrieve it again"
+	app.errorResponse(w, r, http.StatusPreconditionFailed, message)
+}
+
+// unsupportedMediaTypeResponse sends a 415 HTTP status code when the request
+// body is not in one of the accepted media types.
+func (app *application) unsupportedMediaTypeResponse(w http.ResponseWriter, r *http.Request, accepted ...string) {
+	w.Header().Set("Accept-Patch", strings.Join(accepted, ", "))
+
+	message := fmt.Sprintf("the request body must be one of %s", strings.Join(accepted, ", "))
+	app.errorResponse(w, r, http.StatusUnsupportedMediaType, message)
+}
+
+// invalidCredentialsResponse sends a 401 HTTP status code for an incorrect
+// email and password combination.
+func (app *application) invalidCredentialsResponse(w http.ResponseWriter, r *http.Request) {
+	message := "invalid authentication credentials"
+	app.errorResponse(w, r, http.StatusUnauthorized, message)
+}
+
+// invalidAuthenticationTokenResponse sends a 401 HTTP status code for a missing,
+// malformed, expired, or unknown bearer token.
+func (app *application) invalidAuthenticationTokenResponse(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("WWW-Authenticate", "Bearer")
+
+	message := "invalid or missing authentication token"
+	app.errorResponse(w, r, http.StatusUnauthorized, message)
+}
+
+// authenticationRequiredResponse sends a 401 HTTP status code when an
+// anonymous client accesses a protected resource.
+func (app *application) authenticationRequiredResponse(w http.ResponseWriter, r *http.Request) {
+	message := "you must be authenticated to access this resource"
+	app.errorResponse(w, r, http.StatusUnauthorized, message)
+}
+
+// notPermittedResponse sends a 403 HTTP status code when an employee's roles do
+// not grant access to a resource.
+func (app *application) notPermittedResponse(w http.ResponseWriter, r *http.Request) {
+	message := "your employee role does not have the necessary permissions to access this resource"
+	app.errorResponse(w, r, http.StatusForbidden, message)
+}
+
+// dataErrorResponse sends the HTTP status code and error code corresponding to
+// an error returned by the data layer. Unrecognized errors are sent as a
+// server error.
+func (app *application) dataErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	// 404 Not Found
+	case errors.Is(err, data.ErrRecordNotFound):
+		app.codedErrorResponse(w, r, http.StatusNotFound, "not-found", "the requested resource could not be found")
+	case errors.Is(err, data.ErrGuestNotFound):
+		app.codedErrorResponse(w, r, http.StatusNotFound, "guest-not-found", "no guest with the given passport number exists")
+	case errors.Is(err, data.ErrHotelNotFound):
+		app.codedErrorResponse(w, r, http.StatusNotFound, "hotel-not-found", "no hotel with the given id exists")
+	case errors.Is(err, data.ErrRoomNotFound):
+		app.codedErrorResponse(w, r, http.StatusNotFound, "room-not-found", "no room with the given hotel and number exists")
+	case errors.Is(err, data.ErrReservationNotFound):
+		app.codedErrorResponse(w, r, http.StatusNotFound, "reservation-not-found", "no reservation with the given id exists")
+
+	// 409 Conflict
+	case errors.Is(err, data.ErrEditConflict):
+		app.codedErrorResponse(w, r, http.StatusConflict, "edit-conflict", "unable to update the record due to an edit conflict, please try again")
+	case errors.Is(err, data.ErrNoAvailableRoom):
+		app.codedErrorResponse(w, r, http.StatusConflict, "no-available-room", "no room of the requested type is available for the requested dates")
+	case errors.Is(err, data.ErrDuplicatePassport):
+		app.codedErrorResponse(w, r, http.StatusConflict, "duplicate-passport", map[string]string{"passport_number": "a guest with this passport number already exists"})
+	case errors.Is(err, data.ErrDuplicateRecord):
+		app.codedErrorResponse(w, r, http.StatusConflict, "duplicate-record", "a record with the same unique values already exists")
+	case errors.Is(err, data.ErrReservationCanceled):
+		app.codedErrorResponse(w, r, http.StatusConflict, "reservation-canceled", "the reservation has been canceled")
+	case errors.Is(err, data.ErrReservationCompleted):
+		app.codedErrorResponse(w, r, http.StatusConflict, "reservation-completed", "the reservation has already been completed")
+	case errors.Is(err, data.ErrReservationCheckedIn):
+		app.codedErrorResponse(w, r, http.StatusConflict, "reservation-checked-in", "the reservation has already been checked in")
+	case errors.Is(err, data.ErrReservationNotCheckedIn):
+		app.codedErrorResponse(w, r, http.StatusConflict, "reservation-not-checked-in", "the reservation has not been checked in")
+	case errors.Is(err, data.ErrReservationNoShow):
+		app.codedErrorResponse(w, r, http.StatusConflict, "reservation-no-show", "the reservation's stay has ended without a check-in, so it can no longer be canceled")
+	case errors.Is(err, data.ErrIllegalStatusTransition):
+		app.codedErrorResponse(w, r, http.StatusConflict, "illegal-status-transition", "the room cannot change from its current status to the requested status")
+	case errors.Is(err, data.ErrOutstandingTasks):
+		app.codedErrorResponse(w, r, http.StatusConflict, "outstanding-housekeeping-tasks", "the room has housekeeping tasks that are not complete")
+	case errors.Is(err, data.ErrTaskCompleted):
+		app.codedErrorResponse(w, r, http.StatusConflict, "task-completed", "the housekeeping task has already been completed")
+	case errors.Is(err, data.ErrEmployeeTerminated):
+		app.codedErrorResponse(w, r, http.StatusConflict, "employee-terminated", "the employee has already been terminated")
+	case errors.Is(err, data.ErrOverBudget):
+		app.codedErrorResponse(w, r, http.StatusConflict, "over-budget", "the salary would push the department over its budget; an operations manager may override the budget")
+	case errors.Is(err, data.ErrReportResolved):
+		app.codedErrorResponse(w, r, http.StatusConflict, "report-resolved", "the maintenance report has already been resolved")
+	case errors.Is(err, data.ErrReportNotResolved):
+		app.codedErrorResponse(w, r, http.StatusConflict, "report-not-resolved", "the maintenance report has not been resolved")
+	case errors.Is(err, data.ErrRoomNotReady):
+		app.codedErrorResponse(w, r, http.StatusConflict, "room-not-ready", "a room of the reservation is not vacant and clean")
+	case errors.Is(err, data.ErrRoomOutOfOrder):
+		app.codedErrorResponse(w, r, http.StatusConflict, "room-out-of-order", "the room has an unresolved out-of-order maintenance report")
+
+	// 422 Unprocessable Entity
+	case errors.Is(err, data.ErrReservationGuestMismatch):
+		app.codedErrorResponse(w, r, http.StatusUnprocessableEntity, "reservation-guest-mismatch", "the reservation does not belong to the given guest")
+	case errors.Is(err, data.ErrNonexistentRoomType):
+		app.codedErrorResponse(w, r, http.StatusUnprocessableEntity, "nonexistent-room-type", "the given room type does not exist")
+	case errors.Is(err, data.ErrOutsideStayDates):
+		app.codedErrorResponse(w, r, http.StatusUnprocessableEntity, "outside-stay-dates", "the reservation can only be checked in during its stay dates")
+	case errors.Is(err, data.ErrInvalidReference):
+		app.codedErrorResponse(w, r, http.StatusUnprocessableEntity, "invalid-reference", "a referenced record does not exist")
+	case errors.Is(err, data.ErrCheckViolation):
+		app.codedErrorResponse(w, r, http.StatusUnprocessableEntity, "check-violation", "the given values violate a business rule")
+
+	default:
+		app.serverErrorResponse(w, r, err)
+	}
+}
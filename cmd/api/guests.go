@@ -1,8 +1,10 @@
 package main
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"mime"
 	"net/http"
 
 	"github.com/andreshungbz/lab4-database-crud/internal/data"
@@ -49,19 +51,47 @@ func (app *application) createGuestHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	// check whether another guest has the contact email
+	var warnings []string
+
+	if app.config.guests.duplicateEmail != "off" {
+		inUse, err := app.models.Guest.EmailInUse(r.Context(), guest.ContactEmail, guest.PassportNumber)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		if inUse {
+			message := "already belongs to another guest"
+
+			if app.config.guests.duplicateEmail == "block" {
+				app.codedErrorResponse(w, r, http.StatusConflict, "duplicate-email", map[string]string{"contact_email": message})
+				return
+			}
+
+			warnings = append(warnings, "contact_email "+message)
+		}
+	}
+
 	// insert into database
-	err = app.models.Guest.Insert(guest)
+	err = app.models.Guest.Insert(r.Context(), guest)
 	if err != nil {
-		app.serverErrorResponse(w, r, err)
+		app.dataErrorResponse(w, r, err)
 		return
 	}
 
-	// add a header to indicate where the new resource is
+	// add headers to indicate where the new resource is and its version
 	headers := make(http.Header)
 	headers.Set("Location", fmt.Sprintf("/v1/guests/%s", guest.PassportNumber))
+	headers.Set("ETag", app.etag(guest.Version))
+
+	// return JSON response of newly created guest along with any warnings
+	env := envelope{"guest": guest}
+	if len(warnings) > 0 {
+		env["warnings"] = warnings
+	}
 
-	// return JSON response of newly created guest
-	err = app.writeJSON(w, http.StatusCreated, envelope{"guest": guest}, headers)
+	err = app.writeJSON(w, http.StatusCreated, env, headers)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
@@ -74,7 +104,7 @@ func (app *application) showGuestHandler(w http.ResponseWriter, r *http.Request)
 	passport := app.readPassportParam(r)
 
 	// retrieve guest from database
-	guest, err := app.models.Guest.Get(passport)
+	guest, err := app.models.Guest.Get(r.Context(), passport)
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrRecordNotFound):
@@ -85,93 +115,296 @@ func (app *application) showGuestHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// add a header with the guest's version for use with If-Match
+	headers := make(http.Header)
+	headers.Set("ETag", app.etag(guest.Version))
+
 	// return JSON response of retrieved guest
-	err = app.writeJSON(w, http.StatusOK, envelope{"guest": guest}, nil)
+	err = app.writeJSON(w, http.StatusOK, envelope{"guest": guest}, headers)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
 }
 
-// listGuestsHandler returns JSON of all guests. It can be filtered by the
-// guest's name.
+// listGuestsHandler returns JSON of a page of guests. It can be filtered by the
+// guest's name, email, country, city, and creation date, and sorted by name,
+// passport number, creation date, or country.
 func (app *application) listGuestsHandler(w http.ResponseWriter, r *http.Request) {
-	// read the name URL key
+	v := validator.New()
+
+	// read the filter URL keys
 	qs := r.URL.Query()
-	name := app.readString(qs, "name", "")
+
+	var gf data.GuestFilters
+	gf.Name = app.readString(qs, "name", "")
+	gf.Email = app.readString(qs, "email", "")
+	gf.Country = app.readString(qs, "country", "")
+	gf.City = app.readString(qs, "city", "")
+
+	// created_to is inclusive, so the guests created before the next day are selected
+	if from := app.readDate(qs, "created_from", v); !from.IsZero() {
+		gf.CreatedAfter = from.Time()
+	}
+	if to := app.readDate(qs, "created_to", v); !to.IsZero() {
+		gf.CreatedBefore = to.Time().AddDate(0, 0, 1)
+	}
+
+	// read the pagination and sorting URL keys
+	var filters data.Filters
+	filters.Page = app.readInt(qs, "page", 1, v)
+	filters.PageSize = app.readInt(qs, "page_size", 20, v)
+	filters.Cursor = app.readString(qs, "cursor", "")
+	filters.Sort = app.readString(qs, "sort", "passport_number")
+	filters.SortSafelist = data.GuestSortSafelist
+
+	// validate
+	if data.ValidateFilters(v, filters); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
 
 	// retrieve records from the database
-	guests, err := app.models.Guest.GetAll(name)
+	guests, metadata, err := app.models.Guest.GetAll(r.Context(), gf, filters)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
 
 	// return JSON response of the list of guests
-	err = app.writeJSON(w, http.StatusOK, envelope{"guests": guests}, nil)
+	err = app.writeJSON(w, http.StatusOK, envelope{"guests": guests, "metadata": metadata}, nil)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
 }
 
-// updateGuestHandler uses the guest's passport number to retrieve the guest,
-// updates its values with JSON input, and returns the updated guest as JSON
-// output.
-func (app *application) updateGuestHandler(w http.ResponseWriter, r *http.Request) {
-	// read passport parameter
-	passport := app.readPassportParam(r)
+// guestInput is the JSON input for replacing or patching a guest. A null value
+// clears one of the optional person attributes.
+type guestInput struct {
+	ContactEmail optional[string] `json:"contact_email"`
+	ContactPhone optional[string] `json:"contact_phone"`
+	Name         optional[string] `json:"name"`
+	Gender       optional[string] `json:"gender"`
+	Street       optional[string] `json:"street"`
+	City         optional[string] `json:"city"`
+	Country      optional[string] `json:"country"`
+}
 
-	// retrieve guest from database
-	guest, err := app.models.Guest.Get(passport)
-	if err != nil {
-		switch {
-		case errors.Is(err, data.ErrRecordNotFound):
-			app.notFoundResponse(w, r)
-		default:
-			app.serverErrorResponse(w, r, err)
+// missing returns the JSON keys of the fields absent from the input.
+func (input guestInput) missing() []string {
+	fields := map[string]bool{
+		"contact_email": input.ContactEmail.Set,
+		"contact_phone": input.ContactPhone.Set,
+		"name":          input.Name.Set,
+		"gender":        input.Gender.Set,
+		"street":        input.Street.Set,
+		"city":          input.City.Set,
+		"country":       input.Country.Set,
+	}
+
+	var keys []string
+	for key, set := range fields {
+		if !set {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
+// apply copies the fields present in the input onto a guest. Null fields are
+// cleared.
+func (input guestInput) apply(guest *data.Guest) {
+	if input.ContactEmail.Set {
+		guest.ContactEmail = input.ContactEmail.Value
+	}
+	if input.ContactPhone.Set {
+		guest.ContactPhone = input.ContactPhone.Value
+	}
+	if input.Name.Set {
+		guest.Name = input.Name.Value
+	}
+	if input.Gender.Set {
+		guest.Gender = input.Gender.Value
+	}
+	if input.Street.Set {
+		guest.Street = input.Street.Value
+	}
+	if input.City.Set {
+		guest.City = input.City.Value
+	}
+	if input.Country.Set {
+		guest.Country = input.Country.Value
+	}
+}
+
+// guestDocument returns the fields of a guest that can be patched as a JSON
+// document. Empty optional person attributes are null.
+func guestDocument(guest *data.Guest) map[string]json.RawMessage {
+	doc := make(map[string]json.RawMessage)
+
+	fields := map[string]string{
+		"contact_email": guest.ContactEmail,
+		"contact_phone": guest.ContactPhone,
+		"name":          guest.Name,
+		"gender":        guest.Gender,
+		"street":        guest.Street,
+		"city":          guest.City,
+		"country":       guest.Country,
+	}
+
+	for key, value := range fields {
+		doc[key] = json.RawMessage("null")
+		if value != "" {
+			doc[key], _ = json.Marshal(value)
 		}
+	}
+
+	return doc
+}
+
+// Media types accepted when patching a guest. Plain JSON is treated as a merge
+// patch.
+const (
+	mergePatchMediaType = "application/merge-patch+json"
+	jsonPatchMediaType  = "application/json-patch+json"
+)
+
+// replaceGuestHandler uses the guest's passport number to retrieve the guest
+// and replaces its values with JSON input. Every field must be given, with
+// null clearing the optional person attributes.
+func (app *application) replaceGuestHandler(w http.ResponseWriter, r *http.Request) {
+	// retrieve guest from database
+	guest, ok := app.readGuestForUpdate(w, r)
+	if !ok {
 		return
 	}
 
 	// Read JSON input
 
-	var input struct {
-		ContactEmail *string `json:"contact_email"`
-		ContactPhone *string `json:"contact_phone"`
-		Name         *string `json:"name"`
-		Gender       *string `json:"gender"`
-		Street       *string `json:"street"`
-		City         *string `json:"city"`
-		Country      *string `json:"country"`
-	}
+	var input guestInput
 
-	err = app.readJSON(w, r, &input)
+	err := app.readJSON(w, r, &input)
 	if err != nil {
 		app.badRequestResponse(w, r, err)
 		return
 	}
 
-	if input.ContactEmail != nil {
-		guest.ContactEmail = *input.ContactEmail
+	// a replacement must include every field
+	v := validator.New()
+	for _, key := range input.missing() {
+		v.AddError(key, "must be provided")
 	}
-	if input.ContactPhone != nil {
-		guest.ContactPhone = *input.ContactPhone
+
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
 	}
-	if input.Name != nil {
-		guest.Name = *input.Name
+
+	input.apply(guest)
+	app.saveGuest(w, r, guest)
+}
+
+// updateGuestHandler uses the guest's passport number to retrieve the guest and
+// partially updates it with a JSON Merge Patch (RFC 7386), or a JSON Patch
+// (RFC 6902) when sent as application/json-patch+json.
+func (app *application) updateGuestHandler(w http.ResponseWriter, r *http.Request) {
+	mediaType := "application/json"
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, _ = mime.ParseMediaType(contentType)
+	}
+
+	if mediaType != "application/json" && mediaType != mergePatchMediaType && mediaType != jsonPatchMediaType {
+		app.unsupportedMediaTypeResponse(w, r, mergePatchMediaType, jsonPatchMediaType)
+		return
 	}
-	if input.Gender != nil {
-		guest.Gender = *input.Gender
+
+	// retrieve guest from database
+	guest, ok := app.readGuestForUpdate(w, r)
+	if !ok {
+		return
 	}
-	if input.Street != nil {
-		guest.Street = *input.Street
+
+	// Read JSON input
+
+	var input guestInput
+
+	if mediaType == jsonPatchMediaType {
+		var operations []jsonPatchOperation
+
+		err := app.readJSON(w, r, &operations)
+		if err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+
+		// apply the operations to the guest's current fields
+		doc := guestDocument(guest)
+
+		err = applyJSONPatch(doc, operations)
+		if err != nil {
+			switch {
+			case errors.Is(err, errPatchTestFailed):
+				app.codedErrorResponse(w, r, http.StatusConflict, "patch-test-failed", err.Error())
+			default:
+				app.codedErrorResponse(w, r, http.StatusUnprocessableEntity, "invalid-patch", err.Error())
+			}
+			return
+		}
+
+		patched, err := json.Marshal(doc)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		err = json.Unmarshal(patched, &input)
+		if err != nil {
+			app.codedErrorResponse(w, r, http.StatusUnprocessableEntity, "invalid-patch", "patched fields must be strings or null")
+			return
+		}
+	} else {
+		err := app.readJSON(w, r, &input)
+		if err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
 	}
-	if input.City != nil {
-		guest.City = *input.City
+
+	input.apply(guest)
+	app.saveGuest(w, r, guest)
+}
+
+// readGuestForUpdate retrieves the guest of the request's passport parameter
+// and checks that the client's copy is current. If not, an error response is
+// sent and false is returned.
+func (app *application) readGuestForUpdate(w http.ResponseWriter, r *http.Request) (*data.Guest, bool) {
+	// read passport parameter
+	passport := app.readPassportParam(r)
+
+	// retrieve guest from database
+	guest, err := app.models.Guest.Get(r.Context(), passport)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return nil, false
 	}
-	if input.Country != nil {
-		guest.Country = *input.Country
+
+	// reject the update if the client's copy of the guest is outdated
+	if !app.ifMatch(r, app.etag(guest.Version)) {
+		app.preconditionFailedResponse(w, r)
+		return nil, false
 	}
 
+	return guest, true
+}
+
+// saveGuest validates an updated guest, saves it, and returns the updated
+// guest as JSON output.
+func (app *application) saveGuest(w http.ResponseWriter, r *http.Request, guest *data.Guest) {
 	// validate
 	v := validator.New()
 	if data.ValidateGuest(v, guest); !v.Valid() {
@@ -180,19 +413,23 @@ func (app *application) updateGuestHandler(w http.ResponseWriter, r *http.Reques
 	}
 
 	// update record in the database
-	err = app.models.Guest.Update(guest)
+	err := app.models.Guest.Update(r.Context(), guest)
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrEditConflict):
 			app.editConflictResponse(w, r)
 		default:
-			app.serverErrorResponse(w, r, err)
+			app.dataErrorResponse(w, r, err)
 		}
 		return
 	}
 
+	// add a header with the guest's new version
+	headers := make(http.Header)
+	headers.Set("ETag", app.etag(guest.Version))
+
 	// return JSON response of updated guest
-	err = app.writeJSON(w, http.StatusOK, envelope{"guest": guest}, nil)
+	err = app.writeJSON(w, http.StatusOK, envelope{"guest": guest}, headers)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
@@ -206,14 +443,9 @@ func (app *application) deleteGuestHandler(w http.ResponseWriter, r *http.Reques
 	passport := app.readPassportParam(r)
 
 	// delete guest and associated records from the database
-	err := app.models.Guest.Delete(passport)
+	err := app.models.Guest.Delete(r.Context(), passport)
 	if err != nil {
-		switch {
-		case errors.Is(err, data.ErrRecordNotFound):
-			app.notFoundResponse(w, r)
-		default:
-			app.serverErrorResponse(w, r, err)
-		}
+		app.dataErrorResponse(w, r, err)
 		return
 	}
 
@@ -10,6 +10,8 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/andreshungbz/lab4-database-crud/internal/data"
+	"github.com/andreshungbz/lab4-database-crud/internal/validator"
 	"github.com/julienschmidt/httprouter"
 )
 
@@ -117,6 +119,18 @@ func (app *application) readIDParam(r *http.Request) (int64, error) {
 	return id, nil
 }
 
+// readRoomNumberParam validates and returns the given request URL's number parameter.
+func (app *application) readRoomNumberParam(r *http.Request) (int, error) {
+	params := httprouter.ParamsFromContext(r.Context())
+
+	number, err := strconv.Atoi(params.ByName("number"))
+	if err != nil || number < 1 { // ensure the room number is positive
+		return 0, errors.New("invalid number parameter")
+	}
+
+	return number, nil
+}
+
 // readPassportParams validates and returns the given request URL's passport parameter.
 func (app *application) readPassportParam(r *http.Request) string {
 	params := httprouter.ParamsFromContext(r.Context())
@@ -124,6 +138,13 @@ func (app *application) readPassportParam(r *http.Request) string {
 	return params.ByName("passport")
 }
 
+// readDepartmentParam returns the given request URL's department name parameter.
+func (app *application) readDepartmentParam(r *http.Request) string {
+	params := httprouter.ParamsFromContext(r.Context())
+
+	return params.ByName("name")
+}
+
 // readString gets the value of a URL key.
 func (app *application) readString(qs url.Values, key string, defaultValue string) string {
 	s := qs.Get(key)
@@ -134,3 +155,63 @@ func (app *application) readString(qs url.Values, key string, defaultValue strin
 
 	return s
 }
+
+// readInt gets the value of a URL key and converts it to an integer. If the
+// conversion fails, an error is recorded in the Validator.
+func (app *application) readInt(qs url.Values, key string, defaultValue int, v *validator.Validator) int {
+	s := qs.Get(key)
+
+	if s == "" {
+		return defaultValue
+	}
+
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		v.AddError(key, "must be an integer value")
+		return defaultValue
+	}
+
+	return i
+}
+
+// readDate gets the value of a URL key and parses it as a YYYY-MM-DD date. If
+// the parsing fails, an error is recorded in the Validator.
+func (app *application) readDate(qs url.Values, key string, v *validator.Validator) data.Date {
+	s := qs.Get(key)
+
+	if s == "" {
+		return data.Date{}
+	}
+
+	date, err := data.ParseDate(s)
+	if err != nil {
+		v.AddError(key, "must be a date in the form YYYY-MM-DD")
+		return data.Date{}
+	}
+
+	return date
+}
+
+// etag returns a strong entity tag for a record version, suitable for the
+// ETag header.
+func (app *application) etag(version int32) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// ifMatch reports whether the request's If-Match header permits modifying a
+// resource with the given entity tag. A missing header always matches.
+func (app *application) ifMatch(r *http.Request, etag string) bool {
+	header := r.Header.Get("If-Match")
+	if header == "" {
+		return true
+	}
+
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/andreshungbz/lab4-database-crud/internal/data"
+	"github.com/andreshungbz/lab4-database-crud/internal/validator"
+)
+
+// createHotelHandler reads JSON input and creates a hotel, returning it in JSON
+// output.
+func (app *application) createHotelHandler(w http.ResponseWriter, r *http.Request) {
+	// Read JSON input into a Hotel
+
+	var input struct {
+		Name    string `json:"name"`
+		Street  string `json:"street"`
+		City    string `json:"city"`
+		State   string `json:"state"`
+		Country string `json:"country"`
+		Phone   string `json:"phone"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	hotel := &data.Hotel{
+		Name:    input.Name,
+		Street:  input.Street,
+		City:    input.City,
+		State:   input.State,
+		Country: input.Country,
+		Phone:   input.Phone,
+	}
+
+	// validate
+	v := validator.New()
+	if data.ValidateHotel(v, hotel); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	// insert into database
+	err = app.models.Hotel.Insert(r.Context(), hotel)
+	if err != nil {
+		app.dataErrorResponse(w, r, err)
+		return
+	}
+
+	// add a header to indicate where the new resource is
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/hotels/%d", hotel.ID))
+
+	// return JSON response of newly created hotel
+	err = app.writeJSON(w, http.StatusCreated, envelope{"hotel": hotel}, headers)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// showHotelHandler reads a hotel's id and returns a JSON response for that hotel.
+func (app *application) showHotelHandler(w http.ResponseWriter, r *http.Request) {
+	// read id parameter
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	// retrieve hotel from database
+	hotel, err := app.models.Hotel.Get(r.Context(), id)
+	if err != nil {
+		app.dataErrorResponse(w, r, err)
+		return
+	}
+
+	// return JSON response of retrieved hotel
+	err = app.writeJSON(w, http.StatusOK, envelope{"hotel": hotel}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// listHotelsHandler returns JSON of all hotels.
+func (app *application) listHotelsHandler(w http.ResponseWriter, r *http.Request) {
+	// retrieve records from the database
+	hotels, err := app.models.Hotel.GetAll(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// return JSON response of the list of hotels
+	err = app.writeJSON(w, http.StatusOK, envelope{"hotels": hotels}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// updateHotelHandler uses the hotel's id to retrieve the hotel, updates its
+// values with JSON input, and returns the updated hotel as JSON output.
+func (app *application) updateHotelHandler(w http.ResponseWriter, r *http.Request) {
+	// read id parameter
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	// retrieve hotel from database
+	hotel, err := app.models.Hotel.Get(r.Context(), id)
+	if err != nil {
+		app.dataErrorResponse(w, r, err)
+		return
+	}
+
+	// Read JSON input
+
+	var input struct {
+		Name    *string `json:"name"`
+		Street  *string `json:"street"`
+		City    *string `json:"city"`
+		State   *string `json:"state"`
+		Country *string `json:"country"`
+		Phone   *string `json:"phone"`
+	}
+
+	err = app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if input.Name != nil {
+		hotel.Name = *input.Name
+	}
+	if input.Street != nil {
+		hotel.Street = *input.Street
+	}
+	if input.City != nil {
+		hotel.City = *input.City
+	}
+	if input.State != nil {
+		hotel.State = *input.State
+	}
+	if input.Country != nil {
+		hotel.Country = *input.Country
+	}
+	if input.Phone != nil {
+		hotel.Phone = *input.Phone
+	}
+
+	// validate
+	v := validator.New()
+	if data.ValidateHotel(v, hotel); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	// update record in the database
+	err = app.models.Hotel.Update(r.Context(), hotel)
+	if err != nil {
+		app.dataErrorResponse(w, r, err)
+		return
+	}
+
+	// return JSON response of updated hotel
+	err = app.writeJSON(w, http.StatusOK, envelope{"hotel": hotel}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// deleteHotelHandler uses the hotel's id in order to delete its record in the
+// database. Its rooms, employees, and their associated records are also deleted.
+func (app *application) deleteHotelHandler(w http.ResponseWriter, r *http.Request) {
+	// read id parameter
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	// delete hotel and associated records from the database
+	err = app.models.Hotel.Delete(r.Context(), id)
+	if err != nil {
+		app.dataErrorResponse(w, r, err)
+		return
+	}
+
+	// return JSON response indicating success
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "hotel successfully deleted"}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/andreshungbz/lab4-database-crud/internal/data"
+	"github.com/andreshungbz/lab4-database-crud/internal/validator"
+)
+
+// listHousekeepingTasksHandler returns JSON of housekeeping tasks. It can be
+// filtered by hotel, housekeeper, room, and status (open by default).
+func (app *application) listHousekeepingTasksHandler(w http.ResponseWriter, r *http.Request) {
+	// read the filter URL keys
+	v := validator.New()
+	qs := r.URL.Query()
+
+	var tf data.TaskFilters
+	tf.HotelID = int64(app.readInt(qs, "hotel_id", 0, v))
+	tf.HousekeeperID = int64(app.readInt(qs, "housekeeper_id", 0, v))
+	tf.RoomNumber = app.readInt(qs, "room_number", 0, v)
+	tf.Status = app.readString(qs, "status", "open")
+
+	// validate
+	if data.ValidateTaskFilters(v, tf); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	// retrieve records from the database
+	tasks, err := app.models.Housekeeping.GetAll(r.Context(), tf)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// return JSON response of the list of tasks
+	err = app.writeJSON(w, http.StatusOK, envelope{"housekeeping_tasks": tasks}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// showHousekeepingTaskHandler reads a task's id and returns a JSON response for
+// that task.
+func (app *application) showHousekeepingTaskHandler(w http.ResponseWriter, r *http.Request) {
+	// read id parameter
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	// retrieve task from database
+	task, err := app.models.Housekeeping.Get(r.Context(), id)
+	if err != nil {
+		app.dataErrorResponse(w, r, err)
+		return
+	}
+
+	// return JSON response of retrieved task
+	err = app.writeJSON(w, http.StatusOK, envelope{"housekeeping_task": task}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// assignHousekeepingTaskHandler reads a task's id and a housekeeper's id as JSON
+// input, and assigns the task to the housekeeper if they work at the task's
+// hotel during the current shift. The updated task is returned as JSON output.
+func (app *application) assignHousekeepingTaskHandler(w http.ResponseWriter, r *http.Request) {
+	// read id parameter
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	// Read JSON input
+
+	var input struct {
+		HousekeeperID int64 `json:"housekeeper_id"`
+	}
+
+	err = app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	// validate
+	v := validator.New()
+	if v.Check(input.HousekeeperID > 0, "housekeeper_id", "must be a positive integer"); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	// assign the task to the housekeeper
+	task, err := app.models.Housekeeping.Assign(r.Context(), id, input.HousekeeperID, app.config.shifts.Current(time.Now()))
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrHousekeeperNotFound):
+			v.AddError("housekeeper_id", "must refer to an employed housekeeper at the task's hotel")
+			app.failedValidationResponse(w, r, v.Errors)
+		case errors.Is(err, data.ErrWrongShift):
+			v.AddError("housekeeper_id", "must refer to a housekeeper working the current shift")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.dataErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	// return JSON response of the assigned task
+	err = app.writeJSON(w, http.StatusOK, envelope{"housekeeping_task": task}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// completeHousekeepingTaskHandler reads a task's id and marks it complete. If it
+// was the last outstanding task of a vacant/dirty room, the room becomes
+// vacant/clean. The task and any room status change are returned as JSON output.
+func (app *application) completeHousekeepingTaskHandler(w http.ResponseWriter, r *http.Request) {
+	// read id parameter
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	// complete the task on behalf of the authenticated employee
+	task, change, err := app.models.Housekeeping.Complete(r.Context(), id, app.contextGetEmployee(r).ID)
+	if err != nil {
+		app.dataErrorResponse(w, r, err)
+		return
+	}
+
+	// return JSON response of the completed task
+	env := envelope{"housekeeping_task": task, "status_change": change}
+	err = app.writeJSON(w, http.StatusOK, env, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
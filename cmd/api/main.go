@@ -12,9 +12,13 @@ import (
 	"runtime"
 	"sync"
 	"time"
+	_ "time/tzdata"
 
 	"github.com/andreshungbz/lab4-database-crud/internal/data"
+	"github.com/andreshungbz/lab4-database-crud/internal/migrate"
+	"github.com/andreshungbz/lab4-database-crud/internal/validator"
 	"github.com/andreshungbz/lab4-database-crud/internal/vcs"
+	"github.com/andreshungbz/lab4-database-crud/migrations"
 	_ "github.com/lib/pq"
 )
 
@@ -24,13 +28,38 @@ var (
 
 // config stores the API server configuration.
 type config struct {
-	port int    // API server port
-	env  string // (development|staging|production)
-	db   struct {
-		dsn string // data source name
+	file     string             // configuration file
+	port     int                // API server port
+	env      string             // (development|staging|production)
+	db       dbConfig           // database connection
+	refund   data.RefundPolicy  // reservation cancellation refunds
+	shifts   data.ShiftSchedule // housekeeping shift hours
+	timeZone string             // time zone of the hotel
+	guests   struct {
+		duplicateEmail string // (off|warn|block) guests sharing a contact email
 	}
 }
 
+// dbConfig stores the database configuration. The DSN is assembled from the
+// other connection settings if it is not set.
+type dbConfig struct {
+	dsn          string        // data source name
+	host         string        // server host
+	port         int           // server port
+	user         string        // role to connect as
+	password     string        // password of the role
+	name         string        // database name
+	sslMode      string        // (disable|require|verify-ca|verify-full)
+	queryTimeout time.Duration // timeout of each query
+	autoMigrate  bool          // apply migrations on startup
+
+	maxOpenConns   int           // open connections in the pool, unlimited if 0
+	maxIdleConns   int           // idle connections kept in the pool
+	maxIdleTime    time.Duration // idle time before a connection is closed, unlimited if 0
+	maxLifetime    time.Duration // age at which a connection is closed, unlimited if 0
+	connectTimeout time.Duration // time to wait for the database on startup
+}
+
 // application holds the dependencies for the HTTP handlers, helpers, middleware,
 // etc. so that they are all accessible through dependency injection.
 type application struct {
@@ -41,19 +70,18 @@ type application struct {
 }
 
 func main() {
-	var cfg config
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 
-	// FLAGS
-
-	flag.IntVar(&cfg.port, "port", 4000, "API server port")
-	flag.StringVar(&cfg.env, "env", "development", "Environment (development|staging|production)")
-
-	flag.StringVar(&cfg.db.dsn, "db-dsn", "", "PostgreSQL DSN")
+	// CONFIGURATION
 
 	displayVersion := flag.Bool("version", false, "Display program version")
+	displayConfig := flag.Bool("print-config", false, "Display the effective configuration with secrets redacted")
 
-	flag.Parse()
+	cfg, sources, err := loadConfig(flag.CommandLine, os.Args[1:], os.LookupEnv)
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
 
 	// display program version and exit if the version flag was passed
 	if *displayVersion {
@@ -61,9 +89,74 @@ func main() {
 		os.Exit(0)
 	}
 
+	// display the configuration and exit if the print-config flag was passed
+	if *displayConfig {
+		printConfig(os.Stdout, flag.CommandLine, sources)
+		os.Exit(0)
+	}
+
+	// the only positional arguments are those of the migrate subcommand
+	if flag.NArg() > 0 && flag.Arg(0) != "migrate" {
+		logger.Error("unknown command", "command", flag.Arg(0), "usage", migrateUsage)
+		os.Exit(1)
+	}
+
+	if !validator.PermittedValue(cfg.env, "development", "staging", "production") {
+		logger.Error("invalid environment", "value", cfg.env)
+		os.Exit(1)
+	}
+
+	if cfg.db.dsn == "" {
+		logger.Error("no database configured, set -db-dsn or -db-host and the other db connection settings")
+		os.Exit(1)
+	}
+
+	// validate the refund policy
+	v := validator.New()
+	if data.ValidateRefundPolicy(v, cfg.refund); !v.Valid() {
+		for key, message := range v.Errors {
+			logger.Error("invalid refund policy", "field", key, "error", message)
+		}
+		os.Exit(1)
+	}
+
+	// validate the shift schedule in the hotel's time zone
+	cfg.shifts.Location, err = time.LoadLocation(cfg.timeZone)
+	if err != nil {
+		logger.Error("invalid time zone", "value", cfg.timeZone, "error", err)
+		os.Exit(1)
+	}
+
+	if data.ValidateShiftSchedule(v, cfg.shifts); !v.Valid() {
+		for key, message := range v.Errors {
+			logger.Error("invalid shift schedule", "field", key, "error", message)
+		}
+		os.Exit(1)
+	}
+
+	if cfg.db.queryTimeout <= 0 {
+		logger.Error("invalid query timeout", "value", cfg.db.queryTimeout)
+		os.Exit(1)
+	}
+
+	if cfg.db.maxOpenConns < 0 || cfg.db.maxIdleConns < 0 || cfg.db.maxIdleTime < 0 || cfg.db.maxLifetime < 0 {
+		logger.Error("invalid connection pool settings, they must not be negative")
+		os.Exit(1)
+	}
+
+	if cfg.db.connectTimeout <= 0 {
+		logger.Error("invalid connect timeout", "value", cfg.db.connectTimeout)
+		os.Exit(1)
+	}
+
+	if !validator.PermittedValue(cfg.guests.duplicateEmail, "off", "warn", "block") {
+		logger.Error("invalid duplicate email handling", "value", cfg.guests.duplicateEmail)
+		os.Exit(1)
+	}
+
 	// DATABASE
 
-	db, err := openDB(cfg)
+	db, err := openDB(cfg, logger)
 	if err != nil {
 		logger.Error(err.Error())
 		os.Exit(1)
@@ -71,6 +164,40 @@ func main() {
 	defer db.Close()
 	logger.Info("Database connection pool established")
 
+	// MIGRATIONS
+
+	migrator, err := migrate.New(db, migrations.Files)
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+	migrator.Logger = logger
+
+	// run the migrate subcommand instead of the server
+	if flag.Arg(0) == "migrate" {
+		err = runMigrate(migrator, flag.Args()[1:])
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
+	if cfg.db.autoMigrate {
+		err = migrator.Up(context.Background(), 0)
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(1)
+		}
+	}
+
+	// refuse to serve until the schema is up to date
+	err = checkSchemaVersion(context.Background(), migrator)
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+
 	// METRICS
 
 	expvar.NewString("version").Set(version)
@@ -83,12 +210,16 @@ func main() {
 		return time.Now().Unix()
 	}))
 
+	expvar.Publish("database", expvar.Func(func() any {
+		return db.Stats()
+	}))
+
 	// APPLICATION
 
 	app := &application{
 		config: cfg,
 		logger: logger,
-		models: data.NewModels(db),
+		models: data.NewModels(db, cfg.db.queryTimeout),
 	}
 
 	// start the API server
@@ -99,19 +230,21 @@ func main() {
 	}
 }
 
-// openDB connects to the PostgreSQL database using the provided DSN and
-// and returns a pointer to a handler to that database.
-func openDB(cfg config) (*sql.DB, error) {
+// openDB connects to the PostgreSQL database using the provided DSN, configures
+// the connection pool, and returns a pointer to a handler to that database.
+func openDB(cfg config, logger *slog.Logger) (*sql.DB, error) {
 	db, err := sql.Open("postgres", cfg.db.dsn)
 	if err != nil {
 		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	db.SetMaxOpenConns(cfg.db.maxOpenConns)
+	db.SetMaxIdleConns(cfg.db.maxIdleConns)
+	db.SetConnMaxIdleTime(cfg.db.maxIdleTime)
+	db.SetConnMaxLifetime(cfg.db.maxLifetime)
 
-	// test the connection with a ping
-	err = db.PingContext(ctx)
+	// test the connection, waiting for the database if it is still starting
+	err = pingWithRetry(db, cfg.db.connectTimeout, logger)
 	if err != nil {
 		db.Close()
 		return nil, err
@@ -119,3 +252,46 @@ func openDB(cfg config) (*sql.DB, error) {
 
 	return db, nil
 }
+
+// pinger is implemented by *sql.DB.
+type pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// delays between attempts to reach the database on startup
+var (
+	initialRetryDelay = 250 * time.Millisecond
+	maxRetryDelay     = 5 * time.Second
+)
+
+// pingWithRetry pings the database until it responds, doubling the delay after
+// each failed attempt, and returns the last error once timeout has passed.
+func pingWithRetry(db pinger, timeout time.Duration, logger *slog.Logger) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	delay := initialRetryDelay
+	for attempt := 1; ; attempt++ {
+		// an attempt may take at most 5 seconds of the timeout
+		pingCtx, pingCancel := context.WithTimeout(ctx, 5*time.Second)
+		err := db.PingContext(pingCtx)
+		pingCancel()
+		if err == nil {
+			return nil
+		}
+
+		if ctx.Err() != nil {
+			return fmt.Errorf("database unavailable after %d attempts: %w", attempt, err)
+		}
+
+		logger.Warn("database unavailable, retrying", "attempt", attempt, "delay", delay, "error", err)
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return fmt.Errorf("database unavailable after %d attempts: %w", attempt, err)
+		}
+
+		delay = min(delay*2, maxRetryDelay)
+	}
+}
@@ -2,10 +2,18 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/andreshungbz/lab4-database-crud/internal/data"
 )
 
 func TestWriteJSON(t *testing.T) {
@@ -67,3 +75,104 @@ func TestReadJSON(t *testing.T) {
 		t.Errorf("expected Name to be George, got %s", input.Name)
 	}
 }
+
+func TestIfMatch(t *testing.T) {
+	app := &application{}
+	etag := app.etag(3)
+
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{"", true},
+		{`"3"`, true},
+		{"*", true},
+		{`"1", "3"`, true},
+		{`"2"`, false},
+		{`W/"3"`, false},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPut, "/", nil)
+		if tt.header != "" {
+			req.Header.Set("If-Match", tt.header)
+		}
+
+		if got := app.ifMatch(req, etag); got != tt.want {
+			t.Errorf("If-Match %q: expected %t, got %t", tt.header, tt.want, got)
+		}
+	}
+}
+
+func TestQueryCanceledResponse(t *testing.T) {
+	app := &application{logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	err := fmt.Errorf("%w: %w", data.ErrQueryCanceled, context.Canceled)
+
+	clientCtx, cancelClient := context.WithCancel(context.Background())
+	cancelClient()
+
+	shutdownCtx, cancelShutdown := context.WithCancelCause(context.Background())
+	cancelShutdown(errServerShutdown)
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want int
+	}{
+		{"client disconnected", clientCtx, statusClientClosedRequest},
+		{"server shutdown", shutdownCtx, http.StatusServiceUnavailable},
+		{"query timeout", context.Background(), http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequestWithContext(tt.ctx, http.MethodGet, "/", nil)
+
+		app.serverErrorResponse(rr, req, err)
+
+		if rr.Code != tt.want {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.want, rr.Code)
+		}
+	}
+}
+
+// fakePinger fails a number of pings before succeeding.
+type fakePinger struct {
+	failures int
+	pings    int
+}
+
+func (p *fakePinger) PingContext(ctx context.Context) error {
+	p.pings++
+	if p.pings <= p.failures {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func TestPingWithRetry(t *testing.T) {
+	initialDelay, maxDelay := initialRetryDelay, maxRetryDelay
+	initialRetryDelay, maxRetryDelay = time.Millisecond, 4*time.Millisecond
+	t.Cleanup(func() {
+		initialRetryDelay, maxRetryDelay = initialDelay, maxDelay
+	})
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	// the database becomes available after a few attempts
+	p := &fakePinger{failures: 3}
+	err := pingWithRetry(p, time.Second, logger)
+	if err != nil {
+		t.Fatalf("pingWithRetry error: %v", err)
+	}
+	if p.pings != 4 {
+		t.Errorf("Expected 4 pings, got %d", p.pings)
+	}
+
+	// the database never becomes available
+	p = &fakePinger{failures: 1 << 30}
+	err = pingWithRetry(p, 50*time.Millisecond, logger)
+	if err == nil || !strings.Contains(err.Error(), "connection refused") {
+		t.Errorf("Expected the last ping error, got %v", err)
+	}
+}
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/andreshungbz/lab4-database-crud/internal/data"
+	"github.com/andreshungbz/lab4-database-crud/internal/validator"
+)
+
+// createMaintenanceReportHandler reads JSON input and files a maintenance
+// report for a room. Reports are filed by the authenticated housekeeper unless
+// another housekeeper is given.
+func (app *application) createMaintenanceReportHandler(w http.ResponseWriter, r *http.Request) {
+	// Read JSON input
+
+	var input struct {
+		HotelID       int64  `json:"hotel_id"`
+		RoomNumber    int    `json:"room_number"`
+		HousekeeperID *int64 `json:"housekeeper_id"`
+		Description   string `json:"description"`
+		Priority      string `json:"priority"`
+		Category      string `json:"category"`
+		OutOfOrder    bool   `json:"out_of_order"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	report := &data.MaintenanceReport{
+		HotelID:       input.HotelID,
+		RoomNumber:    input.RoomNumber,
+		HousekeeperID: app.contextGetEmployee(r).ID,
+		Description:   input.Description,
+		Priority:      "normal",
+		Category:      "other",
+		OutOfOrder:    input.OutOfOrder,
+	}
+
+	if input.HousekeeperID != nil {
+		report.HousekeeperID = *input.HousekeeperID
+	}
+	if input.Priority != "" {
+		report.Priority = input.Priority
+	}
+	if input.Category != "" {
+		report.Category = input.Category
+	}
+
+	// validate
+	v := validator.New()
+	if data.ValidateMaintenanceReport(v, report); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	// create record in the database
+	err = app.models.Maintenance.Insert(r.Context(), report)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrInvalidReference):
+			v.AddError("housekeeper_id", "must refer to an existing housekeeper")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.dataErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	// return JSON response of created report
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/maintenance-reports/%d", report.ID))
+
+	err = app.writeJSON(w, http.StatusCreated, envelope{"maintenance_report": report}, headers)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// showMaintenanceReportHandler reads a report's id and returns a JSON response
+// for that report.
+func (app *application) showMaintenanceReportHandler(w http.ResponseWriter, r *http.Request) {
+	// read id parameter
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	// retrieve report from database
+	report, err := app.models.Maintenance.Get(r.Context(), id)
+	if err != nil {
+		app.dataErrorResponse(w, r, err)
+		return
+	}
+
+	// return JSON response of retrieved report
+	err = app.writeJSON(w, http.StatusOK, envelope{"maintenance_report": report}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// listMaintenanceReportsHandler returns JSON of maintenance reports. It can be
+// filtered by hotel, room, priority, category, and status (open by default).
+func (app *application) listMaintenanceReportsHandler(w http.ResponseWriter, r *http.Request) {
+	// read the filter URL keys
+	v := validator.New()
+	qs := r.URL.Query()
+
+	var mf data.MaintenanceFilters
+	mf.HotelID = int64(app.readInt(qs, "hotel_id", 0, v))
+	mf.RoomNumber = app.readInt(qs, "room_number", 0, v)
+	mf.Priority = app.readString(qs, "priority", "")
+	mf.Category = app.readString(qs, "category", "")
+	mf.Status = app.readString(qs, "status", "open")
+
+	// validate
+	if data.ValidateMaintenanceFilters(v, mf); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	// retrieve records from the database
+	reports, err := app.models.Maintenance.GetAll(r.Context(), mf)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// return JSON response of the list of reports
+	err = app.writeJSON(w, http.StatusOK, envelope{"maintenance_reports": reports}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// triageMaintenanceReportHandler reads a report's id and JSON input, and
+// partially updates the report's triage details. Out-of-order rooms are not
+// assigned to reservations until the report is resolved.
+func (app *application) triageMaintenanceReportHandler(w http.ResponseWriter, r *http.Request) {
+	// read id parameter
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	// retrieve report from database
+	report, err := app.models.Maintenance.Get(r.Context(), id)
+	if err != nil {
+		app.dataErrorResponse(w, r, err)
+		return
+	}
+
+	// Read JSON input
+
+	var input struct {
+		Description  *string `json:"description"`
+		Priority     *string `json:"priority"`
+		Category     *string `json:"category"`
+		TechnicianID *int64  `json:"technician_id"`
+		OutOfOrder   *bool   `json:"out_of_order"`
+	}
+
+	err = app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if input.Description != nil {
+		report.Description = *input.Description
+	}
+	if input.Priority != nil {
+		report.Priority = *input.Priority
+	}
+	if input.Category != nil {
+		report.Category = *input.Category
+	}
+	if input.TechnicianID != nil {
+		report.TechnicianID = input.TechnicianID
+	}
+	if input.OutOfOrder != nil {
+		report.OutOfOrder = *input.OutOfOrder
+	}
+
+	// validate
+	v := validator.New()
+	if data.ValidateMaintenanceReport(v, report); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	// update record in the database
+	err = app.models.Maintenance.Update(r.Context(), report)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrTechnicianNotFound):
+			v.AddError("technician_id", "must refer to an employed maintenance employee at the report's hotel")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.dataErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	// return JSON response of updated report
+	err = app.writeJSON(w, http.StatusOK, envelope{"maintenance_report": report}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// resolveMaintenanceReportHandler reads a report's id and marks it resolved.
+func (app *application) resolveMaintenanceReportHandler(w http.ResponseWriter, r *http.Request) {
+	// read id parameter
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	// resolve the report
+	report, err := app.models.Maintenance.Resolve(r.Context(), id)
+	if err != nil {
+		app.dataErrorResponse(w, r, err)
+		return
+	}
+
+	// return JSON response of resolved report
+	err = app.writeJSON(w, http.StatusOK, envelope{"maintenance_report": report}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// reopenMaintenanceReportHandler reads a report's id and marks a resolved
+// report open again.
+func (app *application) reopenMaintenanceReportHandler(w http.ResponseWriter, r *http.Request) {
+	// read id parameter
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	// reopen the report
+	report, err := app.models.Maintenance.Reopen(r.Context(), id)
+	if err != nil {
+		app.dataErrorResponse(w, r, err)
+		return
+	}
+
+	// return JSON response of reopened report
+	err = app.writeJSON(w, http.StatusOK, envelope{"maintenance_report": report}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/andreshungbz/lab4-database-crud/internal/migrate"
+)
+
+// migrateUsage describes the arguments of the migrate subcommand.
+const migrateUsage = "usage: api [flags] migrate up [N] | down N|all | goto V | force V | status"
+
+// runMigrate runs the migrate subcommand with its arguments.
+func runMigrate(m *migrate.Migrator, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	ctx := context.Background()
+
+	// commands other than up take exactly one argument
+	var arg string
+	if len(args) > 1 {
+		arg = args[1]
+	}
+	if len(args) > 2 || (arg == "" && args[0] != "up" && args[0] != "status") {
+		return errors.New(migrateUsage)
+	}
+
+	switch args[0] {
+	case "up":
+		n, err := readMigrateArg(arg, 0)
+		if err != nil {
+			return err
+		}
+		return m.Up(ctx, n)
+	case "down":
+		// reverting every migration must be asked for explicitly
+		if arg == "all" {
+			return m.Down(ctx, 0)
+		}
+		n, err := readMigrateArg(arg, 0)
+		if err != nil || n < 1 {
+			return errors.New("down requires a positive number of migrations or all")
+		}
+		return m.Down(ctx, n)
+	case "goto":
+		version, err := readMigrateArg(arg, migrate.NilVersion)
+		if err != nil {
+			return err
+		}
+		return m.Goto(ctx, version)
+	case "force":
+		version, err := readMigrateArg(arg, migrate.NilVersion)
+		if err != nil {
+			return err
+		}
+		return m.Force(ctx, version)
+	case "status":
+		return printMigrationStatus(ctx, m)
+	default:
+		return errors.New(migrateUsage)
+	}
+}
+
+// readMigrateArg converts a number argument, returning the default value if it
+// is empty.
+func readMigrateArg(arg string, defaultValue int) (int, error) {
+	if arg == "" {
+		return defaultValue, nil
+	}
+
+	n, err := strconv.Atoi(arg)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", arg)
+	}
+
+	return n, nil
+}
+
+// printMigrationStatus prints the database version and whether each migration
+// has been applied.
+func printMigrationStatus(ctx context.Context, m *migrate.Migrator) error {
+	version, dirty, err := m.Version(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Version:\t%d (latest %d)\n", version, m.Latest())
+	if dirty {
+		fmt.Println("Dirty:\t\ttrue (fix the failed migration, then force the version)")
+	}
+
+	for _, migration := range m.Migrations() {
+		state := "pending"
+		if migration.Version <= version {
+			state = "applied"
+		}
+		fmt.Printf("%06d\t%s\t%s\n", migration.Version, state, migration.Name)
+	}
+
+	return nil
+}
+
+// checkSchemaVersion returns an error if the database has not been migrated to
+// the latest version. Databases at newer versions are allowed so that an older
+// server can run during a rolling deployment.
+func checkSchemaVersion(ctx context.Context, m *migrate.Migrator) error {
+	version, dirty, err := m.Version(ctx)
+	if err != nil {
+		return err
+	}
+
+	if dirty {
+		return fmt.Errorf("database schema is dirty at version %d", version)
+	}
+
+	if version < m.Latest() {
+		return fmt.Errorf("database schema is at version %d but version %d is required, run migrate up or start with -db-auto-migrate", version, m.Latest())
+	}
+
+	return nil
+}
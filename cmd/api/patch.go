@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// optional is a JSON input field that records whether its key was present and
+// whether its value was null, which a pointer field cannot tell apart.
+type optional[T any] struct {
+	Set   bool
+	Null  bool
+	Value T
+}
+
+// UnmarshalJSON is only called when the field's key is present in the input.
+func (o *optional[T]) UnmarshalJSON(b []byte) error {
+	o.Set = true
+
+	if string(b) == "null" {
+		o.Null = true
+		return nil
+	}
+
+	return json.Unmarshal(b, &o.Value)
+}
+
+// jsonPatchOperation is a single operation of a JSON Patch (RFC 6902) document.
+type jsonPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// errPatchTestFailed is returned when a JSON Patch test operation does not
+// match the current value of a field.
+var errPatchTestFailed = errors.New("patch test operation failed")
+
+// applyJSONPatch applies JSON Patch operations to a document of top-level
+// fields. Only the fields already in the document can be targeted, and removing
+// a field sets it to null. The document is only modified if every operation
+// succeeds.
+func applyJSONPatch(doc map[string]json.RawMessage, operations []jsonPatchOperation) error {
+	patched := make(map[string]json.RawMessage, len(doc))
+	for key, value := range doc {
+		patched[key] = value
+	}
+
+	for i, operation := range operations {
+		field, err := patchField(patched, operation.Path)
+		if err != nil {
+			return fmt.Errorf("operation %d: %w", i, err)
+		}
+
+		switch operation.Op {
+		case "add", "replace":
+			if operation.Value == nil {
+				return fmt.Errorf("operation %d: value must be provided", i)
+			}
+			patched[field] = operation.Value
+		case "remove":
+			patched[field] = json.RawMessage("null")
+		case "copy", "move":
+			from, err := patchField(patched, operation.From)
+			if err != nil {
+				return fmt.Errorf("operation %d: from %w", i, err)
+			}
+
+			value := patched[from]
+			if operation.Op == "move" {
+				patched[from] = json.RawMessage("null")
+			}
+			patched[field] = value
+		case "test":
+			if operation.Value == nil {
+				return fmt.Errorf("operation %d: value must be provided", i)
+			}
+
+			var current, expected any
+			if err := json.Unmarshal(patched[field], &current); err != nil {
+				return err
+			}
+			if err := json.Unmarshal(operation.Value, &expected); err != nil {
+				return fmt.Errorf("operation %d: value must be valid JSON", i)
+			}
+
+			if !reflect.DeepEqual(current, expected) {
+				return fmt.Errorf("%w: %s", errPatchTestFailed, operation.Path)
+			}
+		default:
+			return fmt.Errorf("operation %d: unsupported op %q", i, operation.Op)
+		}
+	}
+
+	for key, value := range patched {
+		doc[key] = value
+	}
+
+	return nil
+}
+
+// patchField returns the field of a document that a JSON Pointer refers to.
+func patchField(doc map[string]json.RawMessage, pointer string) (string, error) {
+	if !strings.HasPrefix(pointer, "/") || strings.Count(pointer, "/") != 1 {
+		return "", fmt.Errorf("path %q must refer to a top-level field", pointer)
+	}
+
+	field := strings.NewReplacer("~1", "/", "~0", "~").Replace(pointer[1:])
+	if _, ok := doc[field]; !ok {
+		return "", fmt.Errorf("path %q does not refer to a field that can be patched", pointer)
+	}
+
+	return field, nil
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestOptionalUnmarshal(t *testing.T) {
+	var input struct {
+		Name   optional[string] `json:"name"`
+		Gender optional[string] `json:"gender"`
+		City   optional[string] `json:"city"`
+	}
+
+	err := json.Unmarshal([]byte(`{"name":"Mae","gender":null}`), &input)
+	if err != nil {
+		t.Fatalf("unmarshal error: %v", err)
+	}
+
+	// assert present, null, and absent keys are told apart
+	if !input.Name.Set || input.Name.Null || input.Name.Value != "Mae" {
+		t.Errorf("name: got %+v", input.Name)
+	}
+	if !input.Gender.Set || !input.Gender.Null {
+		t.Errorf("gender: got %+v", input.Gender)
+	}
+	if input.City.Set {
+		t.Errorf("city: got %+v", input.City)
+	}
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	newDoc := func() map[string]json.RawMessage {
+		return map[string]json.RawMessage{
+			"name":   json.RawMessage(`"Mae"`),
+			"street": json.RawMessage(`"12 Oak St"`),
+			"city":   json.RawMessage(`null`),
+		}
+	}
+
+	tests := []struct {
+		name       string
+		operations string
+		want       map[string]string
+		wantErr    bool
+		wantFailed bool
+	}{
+		{
+			name:       "replace and remove",
+			operations: `[{"op":"replace","path":"/name","value":"May"},{"op":"remove","path":"/street"}]`,
+			want:       map[string]string{"name": `"May"`, "street": `null`, "city": `null`},
+		},
+		{
+			name:       "passing test",
+			operations: `[{"op":"test","path":"/name","value":"Mae"},{"op":"add","path":"/city","value":"Belmopan"}]`,
+			want:       map[string]string{"name": `"Mae"`, "street": `"12 Oak St"`, "city": `"Belmopan"`},
+		},
+		{
+			name:       "move",
+			operations: `[{"op":"move","from":"/street","path":"/city"}]`,
+			want:       map[string]string{"name": `"Mae"`, "street": `null`, "city": `"12 Oak St"`},
+		},
+		{
+			name:       "failing test",
+			operations: `[{"op":"replace","path":"/name","value":"May"},{"op":"test","path":"/name","value":"Mae"}]`,
+			wantErr:    true,
+			wantFailed: true,
+		},
+		{
+			name:       "unknown field",
+			operations: `[{"op":"replace","path":"/passport_number","value":"A1234567"}]`,
+			wantErr:    true,
+		},
+		{
+			name:       "nested path",
+			operations: `[{"op":"replace","path":"/name/first","value":"Mae"}]`,
+			wantErr:    true,
+		},
+		{
+			name:       "unsupported op",
+			operations: `[{"op":"increment","path":"/name"}]`,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		var operations []jsonPatchOperation
+		if err := json.Unmarshal([]byte(tt.operations), &operations); err != nil {
+			t.Fatalf("%s: unmarshal error: %v", tt.name, err)
+		}
+
+		doc := newDoc()
+		err := applyJSONPatch(doc, operations)
+
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected an error", tt.name)
+			}
+			if tt.wantFailed && !errors.Is(err, errPatchTestFailed) {
+				t.Errorf("%s: expected %v, got %v", tt.name, errPatchTestFailed, err)
+			}
+
+			// assert a failed patch leaves the document unchanged
+			for key, value := range newDoc() {
+				if string(doc[key]) != string(value) {
+					t.Errorf("%s: %s changed to %s", tt.name, key, doc[key])
+				}
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+
+		for key, value := range tt.want {
+			if string(doc[key]) != value {
+				t.Errorf("%s: %s = %s, want %s", tt.name, key, doc[key], value)
+			}
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/andreshungbz/lab4-database-crud/internal/data"
+	"github.com/andreshungbz/lab4-database-crud/internal/validator"
+)
+
+// createReservationHandler reads JSON input and books a room for a guest,
+// returning the reservation and its registrations in JSON output.
+func (app *application) createReservationHandler(w http.ResponseWriter, r *http.Request) {
+	// Read JSON input into a Reservation
+
+	var input struct {
+		PassportNumber string    `json:"passport_number"`
+		HotelID        int       `json:"hotel_id"`
+		RoomTypeID     int       `json:"room_type_id"`
+		CheckinDate    data.Date `json:"checkin_date"`
+		CheckoutDate   data.Date `json:"checkout_date"`
+		PaymentMethod  string    `json:"payment_method"`
+		Source         string    `json:"source"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	reservation := &data.Reservation{
+		PassportNumber: input.PassportNumber,
+		CheckinDate:    input.CheckinDate,
+		CheckoutDate:   input.CheckoutDate,
+		PaymentMethod:  input.PaymentMethod,
+		Source:         input.Source,
+	}
+
+	// validate
+	v := validator.New()
+	if data.ValidateReservation(v, reservation, input.HotelID, input.RoomTypeID); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	// ensure the guest exists and retrieve their id
+	guest, err := app.models.Guest.Get(r.Context(), reservation.PassportNumber)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			v.AddError("passport_number", "no guest with this passport number exists")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	reservation.GuestID = guest.ID
+
+	// insert into database
+	err = app.models.Reservation.Insert(r.Context(), reservation, input.HotelID, input.RoomTypeID)
+	if err != nil {
+		app.dataErrorResponse(w, r, err)
+		return
+	}
+
+	// add a header to indicate where the new resource is
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/reservations/%d", reservation.ID))
+
+	// return JSON response of newly created reservation
+	err = app.writeJSON(w, http.StatusCreated, envelope{"reservation": reservation}, headers)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// showReservationHandler reads a reservation's id and returns a JSON response
+// for that reservation.
+func (app *application) showReservationHandler(w http.ResponseWriter, r *http.Request) {
+	// read id parameter
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	// retrieve reservation from database
+	reservation, err := app.models.Reservation.Get(r.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	// return JSON response of retrieved reservation
+	err = app.writeJSON(w, http.StatusOK, envelope{"reservation": reservation}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// listReservationsHandler returns JSON of all reservations. It can be filtered
+// by the guest's passport number.
+func (app *application) listReservationsHandler(w http.ResponseWriter, r *http.Request) {
+	// read the filter URL keys
+	v := validator.New()
+	qs := r.URL.Query()
+
+	var rf data.ReservationFilters
+	rf.Passport = app.readString(qs, "passport_number", "")
+	rf.Status = app.readString(qs, "status", "all")
+
+	// validate
+	if data.ValidateReservationFilters(v, rf); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	// retrieve records from the database
+	reservations, err := app.models.Reservation.GetAll(r.Context(), rf)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// return JSON response of the list of reservations
+	err = app.writeJSON(w, http.StatusOK, envelope{"reservations": reservations}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// checkInReservationHandler checks a guest into the rooms of a reservation and
+// returns the updated reservation as JSON output.
+func (app *application) checkInReservationHandler(w http.ResponseWriter, r *http.Request) {
+	// read id parameter
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	// check in and set rooms to occupied/clean
+	err = app.models.Reservation.CheckIn(r.Context(), id, app.contextGetEmployee(r).ID)
+	if err != nil {
+		app.dataErrorResponse(w, r, err)
+		return
+	}
+
+	app.writeReservation(w, r, id)
+}
+
+// checkOutReservationHandler completes a reservation, queuing housekeeping for
+// its rooms, and returns the updated reservation as JSON output.
+func (app *application) checkOutReservationHandler(w http.ResponseWriter, r *http.Request) {
+	// read id parameter
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	// check out, set rooms to vacant/dirty, and create housekeeping tasks
+	err = app.models.Reservation.CheckOut(r.Context(), id, app.contextGetEmployee(r).ID)
+	if err != nil {
+		app.dataErrorResponse(w, r, err)
+		return
+	}
+
+	app.writeReservation(w, r, id)
+}
+
+// listGuestReservationsHandler reads a guest's passport and returns JSON of
+// the guest's reservations with their registrations. It can be filtered by
+// status (upcoming, past, or canceled).
+func (app *application) listGuestReservationsHandler(w http.ResponseWriter, r *http.Request) {
+	// read passport parameter
+	passport := app.readPassportParam(r)
+
+	// read the status URL key
+	v := validator.New()
+	qs := r.URL.Query()
+
+	rf := data.ReservationFilters{
+		Passport: passport,
+		Status:   app.readString(qs, "status", "all"),
+	}
+
+	// validate
+	if data.ValidateReservationFilters(v, rf); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	// ensure the guest exists
+	_, err := app.models.Guest.Get(r.Context(), passport)
+	if err != nil {
+		app.dataErrorResponse(w, r, err)
+		return
+	}
+
+	// retrieve records from the database
+	reservations, err := app.models.Reservation.GetAll(r.Context(), rf)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// return JSON response of the guest's reservations
+	err = app.writeJSON(w, http.StatusOK, envelope{"reservations": reservations}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// cancelReservationHandler reads a reservation's id and cancels it, releasing
+// its rooms. The refund owed under the configured refund policy is recorded
+// and returned as JSON output along with the reservation.
+func (app *application) cancelReservationHandler(w http.ResponseWriter, r *http.Request) {
+	// read id parameter
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	// cancel and record the refund
+	refund, err := app.models.Reservation.Cancel(r.Context(), id, app.config.refund, app.contextGetEmployee(r).ID)
+	if err != nil {
+		app.dataErrorResponse(w, r, err)
+		return
+	}
+
+	reservation, err := app.models.Reservation.Get(r.Context(), id)
+	if err != nil {
+		app.dataErrorResponse(w, r, err)
+		return
+	}
+
+	// return JSON response of the canceled reservation and its refund
+	err = app.writeJSON(w, http.StatusOK, envelope{"reservation": reservation, "refund": refund}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// writeReservation retrieves a reservation and writes it as a JSON response.
+func (app *application) writeReservation(w http.ResponseWriter, r *http.Request, id int64) {
+	reservation, err := app.models.Reservation.Get(r.Context(), id)
+	if err != nil {
+		app.dataErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"reservation": reservation}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// deleteReservationHandler uses the reservation's id in order to delete its
+// record in the database. Corresponding records in registration are also deleted.
+func (app *application) deleteReservationHandler(w http.ResponseWriter, r *http.Request) {
+	// read id parameter
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	// delete reservation and associated records from the database
+	err = app.models.Reservation.Delete(r.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	// return JSON response indicating success
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "reservation successfully deleted"}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
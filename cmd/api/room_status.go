@@ -0,0 +1,88 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/andreshungbz/lab4-database-crud/internal/data"
+	"github.com/andreshungbz/lab4-database-crud/internal/validator"
+)
+
+// updateRoomStatusHandler reads a room's hotel id and number along with a
+// status code as JSON input, changes the room's status if the transition is
+// legal, and returns the recorded status change as JSON output.
+func (app *application) updateRoomStatusHandler(w http.ResponseWriter, r *http.Request) {
+	// read id and number parameters
+	hotelID, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	number, err := app.readRoomNumberParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	// Read JSON input
+
+	var input struct {
+		StatusCode string `json:"status_code"`
+	}
+
+	err = app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	// validate
+	v := validator.New()
+	if data.ValidateRoomStatus(v, input.StatusCode); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	// change the status on behalf of the authenticated employee
+	change, err := app.models.RoomStatus.Update(r.Context(), hotelID, number, input.StatusCode, app.contextGetEmployee(r).ID)
+	if err != nil {
+		app.dataErrorResponse(w, r, err)
+		return
+	}
+
+	// return JSON response of the status change
+	err = app.writeJSON(w, http.StatusOK, envelope{"status_change": change}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// listRoomStatusHistoryHandler reads a room's hotel id and number and returns
+// JSON of its status changes, most recent first.
+func (app *application) listRoomStatusHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	// read id and number parameters
+	hotelID, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	number, err := app.readRoomNumberParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	// retrieve history from the database
+	history, err := app.models.RoomStatus.GetHistory(r.Context(), hotelID, number)
+	if err != nil {
+		app.dataErrorResponse(w, r, err)
+		return
+	}
+
+	// return JSON response of the status history
+	err = app.writeJSON(w, http.StatusOK, envelope{"status_history": history}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
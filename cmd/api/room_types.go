@@ -0,0 +1,196 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/andreshungbz/lab4-database-crud/internal/data"
+	"github.com/andreshungbz/lab4-database-crud/internal/validator"
+)
+
+// createRoomTypeHandler reads JSON input and creates a room type, returning it
+// in JSON output.
+func (app *application) createRoomTypeHandler(w http.ResponseWriter, r *http.Request) {
+	// Read JSON input into a RoomType
+
+	var input struct {
+		Title        string  `json:"title"`
+		BaseRate     float64 `json:"base_rate"`
+		MaxOccupancy int     `json:"max_occupancy"`
+		BedCount     int     `json:"bed_count"`
+		HasBalcony   bool    `json:"has_balcony"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	roomType := &data.RoomType{
+		Title:        input.Title,
+		BaseRate:     input.BaseRate,
+		MaxOccupancy: input.MaxOccupancy,
+		BedCount:     input.BedCount,
+		HasBalcony:   input.HasBalcony,
+	}
+
+	// validate
+	v := validator.New()
+	if data.ValidateRoomType(v, roomType); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	// insert into database
+	err = app.models.RoomType.Insert(r.Context(), roomType)
+	if err != nil {
+		app.dataErrorResponse(w, r, err)
+		return
+	}
+
+	// add a header to indicate where the new resource is
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/room-types/%d", roomType.ID))
+
+	// return JSON response of newly created room type
+	err = app.writeJSON(w, http.StatusCreated, envelope{"room_type": roomType}, headers)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// showRoomTypeHandler reads a room type's id and returns a JSON response for
+// that room type.
+func (app *application) showRoomTypeHandler(w http.ResponseWriter, r *http.Request) {
+	// read id parameter
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	// retrieve room type from database
+	roomType, err := app.models.RoomType.Get(r.Context(), id)
+	if err != nil {
+		app.dataErrorResponse(w, r, err)
+		return
+	}
+
+	// return JSON response of retrieved room type
+	err = app.writeJSON(w, http.StatusOK, envelope{"room_type": roomType}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// listRoomTypesHandler returns JSON of all room types.
+func (app *application) listRoomTypesHandler(w http.ResponseWriter, r *http.Request) {
+	// retrieve records from the database
+	roomTypes, err := app.models.RoomType.GetAll(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// return JSON response of the list of room types
+	err = app.writeJSON(w, http.StatusOK, envelope{"room_types": roomTypes}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// updateRoomTypeHandler uses the room type's id to retrieve the room type,
+// updates its values with JSON input, and returns the updated room type as
+// JSON output.
+func (app *application) updateRoomTypeHandler(w http.ResponseWriter, r *http.Request) {
+	// read id parameter
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	// retrieve room type from database
+	roomType, err := app.models.RoomType.Get(r.Context(), id)
+	if err != nil {
+		app.dataErrorResponse(w, r, err)
+		return
+	}
+
+	// Read JSON input
+
+	var input struct {
+		Title        *string  `json:"title"`
+		BaseRate     *float64 `json:"base_rate"`
+		MaxOccupancy *int     `json:"max_occupancy"`
+		BedCount     *int     `json:"bed_count"`
+		HasBalcony   *bool    `json:"has_balcony"`
+	}
+
+	err = app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if input.Title != nil {
+		roomType.Title = *input.Title
+	}
+	if input.BaseRate != nil {
+		roomType.BaseRate = *input.BaseRate
+	}
+	if input.MaxOccupancy != nil {
+		roomType.MaxOccupancy = *input.MaxOccupancy
+	}
+	if input.BedCount != nil {
+		roomType.BedCount = *input.BedCount
+	}
+	if input.HasBalcony != nil {
+		roomType.HasBalcony = *input.HasBalcony
+	}
+
+	// validate
+	v := validator.New()
+	if data.ValidateRoomType(v, roomType); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	// update record in the database
+	err = app.models.RoomType.Update(r.Context(), roomType)
+	if err != nil {
+		app.dataErrorResponse(w, r, err)
+		return
+	}
+
+	// return JSON response of updated room type
+	err = app.writeJSON(w, http.StatusOK, envelope{"room_type": roomType}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// deleteRoomTypeHandler uses the room type's id in order to delete its record
+// in the database. Rooms of that type are also deleted.
+func (app *application) deleteRoomTypeHandler(w http.ResponseWriter, r *http.Request) {
+	// read id parameter
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	// delete room type and associated records from the database
+	err = app.models.RoomType.Delete(r.Context(), id)
+	if err != nil {
+		app.dataErrorResponse(w, r, err)
+		return
+	}
+
+	// return JSON response indicating success
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "room type successfully deleted"}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/andreshungbz/lab4-database-crud/internal/data"
+	"github.com/andreshungbz/lab4-database-crud/internal/validator"
+)
+
+// createRoomHandler reads a hotel's id and JSON input and creates a room in that
+// hotel, returning it in JSON output.
+func (app *application) createRoomHandler(w http.ResponseWriter, r *http.Request) {
+	// read id parameter
+	hotelID, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	// Read JSON input into a Room
+
+	var input struct {
+		Number     int   `json:"number"`
+		RoomTypeID int64 `json:"room_type_id"`
+		Floor      int   `json:"floor"`
+	}
+
+	err = app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	room := &data.Room{
+		HotelID:    hotelID,
+		Number:     input.Number,
+		RoomTypeID: input.RoomTypeID,
+		Floor:      input.Floor,
+	}
+
+	// validate
+	v := validator.New()
+	if data.ValidateRoom(v, room); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	// insert into database
+	err = app.models.Room.Insert(r.Context(), room)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrInvalidReference):
+			v.AddError("room_type_id", "must refer to an existing room type")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.dataErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	// add a header to indicate where the new resource is
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/hotels/%d/rooms/%d", room.HotelID, room.Number))
+
+	// return JSON response of newly created room
+	err = app.writeJSON(w, http.StatusCreated, envelope{"room": room}, headers)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// showRoomHandler reads a room's hotel id and number and returns a JSON
+// response for that room.
+func (app *application) showRoomHandler(w http.ResponseWriter, r *http.Request) {
+	// read id and number parameters
+	hotelID, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	number, err := app.readRoomNumberParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	// retrieve room from database
+	room, err := app.models.Room.Get(r.Context(), hotelID, number)
+	if err != nil {
+		app.dataErrorResponse(w, r, err)
+		return
+	}
+
+	// return JSON response of retrieved room
+	err = app.writeJSON(w, http.StatusOK, envelope{"room": room}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// listRoomsHandler returns JSON of all rooms in a hotel. It can be filtered by
+// floor, room type, and status code.
+func (app *application) listRoomsHandler(w http.ResponseWriter, r *http.Request) {
+	// read id parameter
+	hotelID, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	// read the filter URL keys
+	v := validator.New()
+	qs := r.URL.Query()
+
+	var rf data.RoomFilters
+	rf.Floor = app.readInt(qs, "floor", 0, v)
+	rf.RoomTypeID = int64(app.readInt(qs, "room_type_id", 0, v))
+	rf.StatusCode = app.readString(qs, "status_code", "")
+
+	// validate
+	if data.ValidateRoomFilters(v, rf); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	// ensure the hotel exists
+	_, err = app.models.Hotel.Get(r.Context(), hotelID)
+	if err != nil {
+		app.dataErrorResponse(w, r, err)
+		return
+	}
+
+	// retrieve records from the database
+	rooms, err := app.models.Room.GetAll(r.Context(), hotelID, rf)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// return JSON response of the list of rooms
+	err = app.writeJSON(w, http.StatusOK, envelope{"rooms": rooms}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// updateRoomHandler uses the room's hotel id and number to retrieve the room,
+// updates its type and floor with JSON input, and returns the updated room as
+// JSON output. The status code is changed through updateRoomStatusHandler.
+func (app *application) updateRoomHandler(w http.ResponseWriter, r *http.Request) {
+	// read id and number parameters
+	hotelID, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	number, err := app.readRoomNumberParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	// retrieve room from database
+	room, err := app.models.Room.Get(r.Context(), hotelID, number)
+	if err != nil {
+		app.dataErrorResponse(w, r, err)
+		return
+	}
+
+	// Read JSON input
+
+	var input struct {
+		RoomTypeID *int64 `json:"room_type_id"`
+		Floor      *int   `json:"floor"`
+	}
+
+	err = app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if input.RoomTypeID != nil {
+		room.RoomTypeID = *input.RoomTypeID
+	}
+	if input.Floor != nil {
+		room.Floor = *input.Floor
+	}
+
+	// validate
+	v := validator.New()
+	if data.ValidateRoom(v, room); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	// update record in the database
+	err = app.models.Room.Update(r.Context(), room)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrInvalidReference):
+			v.AddError("room_type_id", "must refer to an existing room type")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.dataErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	// return JSON response of updated room
+	err = app.writeJSON(w, http.StatusOK, envelope{"room": room}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// deleteRoomHandler uses the room's hotel id and number in order to delete its
+// record in the database. Its registrations and housekeeping records are also
+// deleted.
+func (app *application) deleteRoomHandler(w http.ResponseWriter, r *http.Request) {
+	// read id and number parameters
+	hotelID, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	number, err := app.readRoomNumberParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	// delete room and associated records from the database
+	err = app.models.Room.Delete(r.Context(), hotelID, number)
+	if err != nil {
+		app.dataErrorResponse(w, r, err)
+		return
+	}
+
+	// return JSON response indicating success
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "room successfully deleted"}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
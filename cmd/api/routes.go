@@ -4,6 +4,7 @@ import (
 	"expvar"
 	"net/http"
 
+	"github.com/andreshungbz/lab4-database-crud/internal/data"
 	"github.com/julienschmidt/httprouter"
 )
 
@@ -20,15 +21,85 @@ func (app *application) routes() http.Handler {
 	router.HandlerFunc(http.MethodGet, "/v1/healthcheck", app.healthcheckHandler)
 
 	// Guest routes
-	router.HandlerFunc(http.MethodGet, "/v1/guests/:passport", app.showGuestHandler)
-	router.HandlerFunc(http.MethodGet, "/v1/guests", app.listGuestsHandler)
-	router.HandlerFunc(http.MethodPost, "/v1/guests", app.createGuestHandler)
-	router.HandlerFunc(http.MethodPut, "/v1/guests/:passport", app.updateGuestHandler)
-	router.HandlerFunc(http.MethodPatch, "/v1/guests/:passport", app.updateGuestHandler)
-	router.HandlerFunc(http.MethodDelete, "/v1/guests/:passport", app.deleteGuestHandler)
+	router.HandlerFunc(http.MethodGet, "/v1/guests/:passport", app.requirePermission(data.PermissionGuestsRead, app.showGuestHandler))
+	router.HandlerFunc(http.MethodGet, "/v1/guests", app.requirePermission(data.PermissionGuestsRead, app.listGuestsHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/guests", app.requirePermission(data.PermissionGuestsWrite, app.createGuestHandler))
+	router.HandlerFunc(http.MethodPut, "/v1/guests/:passport", app.requirePermission(data.PermissionGuestsWrite, app.replaceGuestHandler))
+	router.HandlerFunc(http.MethodPatch, "/v1/guests/:passport", app.requirePermission(data.PermissionGuestsWrite, app.updateGuestHandler))
+	router.HandlerFunc(http.MethodDelete, "/v1/guests/:passport", app.requirePermission(data.PermissionGuestsWrite, app.deleteGuestHandler))
+	router.HandlerFunc(http.MethodGet, "/v1/guests/:passport/reservations", app.requirePermission(data.PermissionReservationsRead, app.listGuestReservationsHandler))
+
+	// Hotel routes (reads are public for the booking website)
+	router.HandlerFunc(http.MethodGet, "/v1/hotels/:id", app.showHotelHandler)
+	router.HandlerFunc(http.MethodGet, "/v1/hotels", app.listHotelsHandler)
+	router.HandlerFunc(http.MethodPost, "/v1/hotels", app.requirePermission(data.PermissionInventoryWrite, app.createHotelHandler))
+	router.HandlerFunc(http.MethodPatch, "/v1/hotels/:id", app.requirePermission(data.PermissionInventoryWrite, app.updateHotelHandler))
+	router.HandlerFunc(http.MethodDelete, "/v1/hotels/:id", app.requirePermission(data.PermissionInventoryWrite, app.deleteHotelHandler))
+
+	// Availability routes (public for the booking website)
+	router.HandlerFunc(http.MethodGet, "/v1/hotels/:id/availability", app.showAvailabilityHandler)
+
+	// Room type routes (reads are public for the booking website)
+	router.HandlerFunc(http.MethodGet, "/v1/room-types/:id", app.showRoomTypeHandler)
+	router.HandlerFunc(http.MethodGet, "/v1/room-types", app.listRoomTypesHandler)
+	router.HandlerFunc(http.MethodPost, "/v1/room-types", app.requirePermission(data.PermissionInventoryWrite, app.createRoomTypeHandler))
+	router.HandlerFunc(http.MethodPatch, "/v1/room-types/:id", app.requirePermission(data.PermissionInventoryWrite, app.updateRoomTypeHandler))
+	router.HandlerFunc(http.MethodDelete, "/v1/room-types/:id", app.requirePermission(data.PermissionInventoryWrite, app.deleteRoomTypeHandler))
+
+	// Room routes
+	router.HandlerFunc(http.MethodGet, "/v1/hotels/:id/rooms/:number", app.requirePermission(data.PermissionRoomsRead, app.showRoomHandler))
+	router.HandlerFunc(http.MethodGet, "/v1/hotels/:id/rooms", app.requirePermission(data.PermissionRoomsRead, app.listRoomsHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/hotels/:id/rooms", app.requirePermission(data.PermissionInventoryWrite, app.createRoomHandler))
+	router.HandlerFunc(http.MethodPatch, "/v1/hotels/:id/rooms/:number", app.requirePermission(data.PermissionInventoryWrite, app.updateRoomHandler))
+	router.HandlerFunc(http.MethodDelete, "/v1/hotels/:id/rooms/:number", app.requirePermission(data.PermissionInventoryWrite, app.deleteRoomHandler))
+
+	// Room status routes
+	router.HandlerFunc(http.MethodPatch, "/v1/hotels/:id/rooms/:number/status", app.requirePermission(data.PermissionRoomsStatus, app.updateRoomStatusHandler))
+	router.HandlerFunc(http.MethodGet, "/v1/hotels/:id/rooms/:number/status-history", app.requirePermission(data.PermissionRoomsRead, app.listRoomStatusHistoryHandler))
+
+	// Housekeeping routes
+	router.HandlerFunc(http.MethodGet, "/v1/housekeeping-tasks", app.requirePermission(data.PermissionTasksRead, app.listHousekeepingTasksHandler))
+	router.HandlerFunc(http.MethodGet, "/v1/housekeeping-tasks/:id", app.requirePermission(data.PermissionTasksRead, app.showHousekeepingTaskHandler))
+	router.HandlerFunc(http.MethodPut, "/v1/housekeeping-tasks/:id/assignee", app.requirePermission(data.PermissionTasksWrite, app.assignHousekeepingTaskHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/housekeeping-tasks/:id/complete", app.requirePermission(data.PermissionTasksWrite, app.completeHousekeepingTaskHandler))
+
+	// Employee routes
+	router.HandlerFunc(http.MethodGet, "/v1/employees", app.requirePermission(data.PermissionEmployeesRead, app.listEmployeesHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/employees", app.requirePermission(data.PermissionEmployeesWrite, app.createEmployeeHandler))
+	router.HandlerFunc(http.MethodGet, "/v1/employees/:id", app.requirePermission(data.PermissionEmployeesRead, app.showEmployeeHandler))
+	router.HandlerFunc(http.MethodPatch, "/v1/employees/:id", app.requirePermission(data.PermissionEmployeesWrite, app.updateEmployeeHandler))
+	router.HandlerFunc(http.MethodDelete, "/v1/employees/:id", app.requirePermission(data.PermissionEmployeesWrite, app.terminateEmployeeHandler))
+	router.HandlerFunc(http.MethodGet, "/v1/employees/:id/reports", app.requirePermission(data.PermissionEmployeesRead, app.listEmployeeReportsHandler))
+
+	// Department routes
+	router.HandlerFunc(http.MethodGet, "/v1/departments", app.requirePermission(data.PermissionFinancesRead, app.listDepartmentsHandler))
+	router.HandlerFunc(http.MethodGet, "/v1/departments/:name/payroll", app.requirePermission(data.PermissionFinancesRead, app.showDepartmentPayrollHandler))
+
+	// Maintenance routes
+	router.HandlerFunc(http.MethodGet, "/v1/maintenance-reports", app.requirePermission(data.PermissionRoomsRead, app.listMaintenanceReportsHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/maintenance-reports", app.requirePermission(data.PermissionMaintenanceWrite, app.createMaintenanceReportHandler))
+	router.HandlerFunc(http.MethodGet, "/v1/maintenance-reports/:id", app.requirePermission(data.PermissionRoomsRead, app.showMaintenanceReportHandler))
+	router.HandlerFunc(http.MethodPatch, "/v1/maintenance-reports/:id", app.requirePermission(data.PermissionMaintenanceWrite, app.triageMaintenanceReportHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/maintenance-reports/:id/resolve", app.requirePermission(data.PermissionMaintenanceWrite, app.resolveMaintenanceReportHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/maintenance-reports/:id/reopen", app.requirePermission(data.PermissionMaintenanceWrite, app.reopenMaintenanceReportHandler))
+
+	// Reservation routes
+	router.HandlerFunc(http.MethodGet, "/v1/reservations/:id", app.requirePermission(data.PermissionReservationsRead, app.showReservationHandler))
+	router.HandlerFunc(http.MethodGet, "/v1/reservations", app.requirePermission(data.PermissionReservationsRead, app.listReservationsHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/reservations", app.requirePermission(data.PermissionReservationsWrite, app.createReservationHandler))
+	router.HandlerFunc(http.MethodDelete, "/v1/reservations/:id", app.requirePermission(data.PermissionReservationsWrite, app.deleteReservationHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/reservations/:id/check-in", app.requirePermission(data.PermissionReservationsWrite, app.checkInReservationHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/reservations/:id/check-out", app.requirePermission(data.PermissionReservationsWrite, app.checkOutReservationHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/reservations/:id/cancel", app.requirePermission(data.PermissionReservationsWrite, app.cancelReservationHandler))
+
+	// Token routes
+	router.HandlerFunc(http.MethodPost, "/v1/tokens/authentication", app.createAuthenticationTokenHandler)
+
+	// Account routes
+	router.HandlerFunc(http.MethodPut, "/v1/account/password", app.requireAuthenticatedEmployee(app.updateAccountPasswordHandler))
 
 	// Metrics debugging route
 	router.Handler(http.MethodGet, "/debug/vars", expvar.Handler())
 
-	return app.recoverPanic(router)
+	return app.recoverPanic(app.authenticate(router))
 }
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -12,11 +13,20 @@ import (
 	"time"
 )
 
+// errServerShutdown is the cause of the cancellation of in-flight requests when
+// the server shuts down.
+var errServerShutdown = errors.New("server shutting down")
+
 // serve starts the HTTP server and monitors for shutdown signals so that the server
 // and background tasks can gracefully terminate.
 func (app *application) serve() error {
 	// HTTP Server Configuration
 
+	// request contexts are canceled if shutdown's grace period expires so
+	// in-flight queries stop
+	baseCtx, cancelBase := context.WithCancelCause(context.Background())
+	defer cancelBase(nil)
+
 	srv := &http.Server{
 		Addr:         fmt.Sprintf(":%d", app.config.port),
 		Handler:      app.routes(),
@@ -24,6 +34,9 @@ func (app *application) serve() error {
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 30 * time.Second,
 		ErrorLog:     slog.NewLogLogger(app.logger.Handler(), slog.LevelError),
+		BaseContext: func(net.Listener) context.Context {
+			return baseCtx
+		},
 	}
 
 	// Goroutine for gracefully shutting down HTTP Server on SIGINT (Ctrl + C) and SIGTERM (pkill)
@@ -41,6 +54,13 @@ func (app *application) serve() error {
 		// allow HTTP server to close any remaining connections with a 30-second grace period
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
+
+		// requests still running when the grace period expires are canceled
+		stop := context.AfterFunc(ctx, func() {
+			cancelBase(errServerShutdown)
+		})
+		defer stop()
+
 		err := srv.Shutdown(ctx)
 		if err != nil {
 			shutdownError <- err
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/andreshungbz/lab4-database-crud/internal/data"
+	"github.com/andreshungbz/lab4-database-crud/internal/validator"
+)
+
+// createAuthenticationTokenHandler reads an employee's work email and password
+// as JSON input and returns a new authentication token in JSON output.
+func (app *application) createAuthenticationTokenHandler(w http.ResponseWriter, r *http.Request) {
+	// Read JSON input
+
+	var input struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	// validate
+	v := validator.New()
+	data.ValidateEmail(v, input.Email)
+	data.ValidatePasswordPlaintext(v, input.Password)
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	// retrieve employee from database
+	employee, err := app.models.Employee.GetByEmail(r.Context(), input.Email)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.invalidCredentialsResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	// former employees are treated the same as unknown emails
+	if !employee.Employed {
+		app.invalidCredentialsResponse(w, r)
+		return
+	}
+
+	match, needsRehash, err := employee.Password.Matches(input.Password)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !match {
+		app.invalidCredentialsResponse(w, r)
+		return
+	}
+
+	// upgrade legacy hashes now that the plaintext is known; failing to do so
+	// should not prevent the employee from authenticating
+	if needsRehash {
+		err = employee.Password.Set(input.Password)
+		if err == nil {
+			err = app.models.Employee.UpdatePassword(r.Context(), employee)
+		}
+		if err != nil {
+			app.logError(r, err)
+		}
+	}
+
+	// create a token that expires in 24 hours
+	token, err := app.models.Token.New(r.Context(), employee.ID, 24*time.Hour, data.ScopeAuthentication)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// return JSON response of the token
+	err = app.writeJSON(w, http.StatusCreated, envelope{"authentication_token": token}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -4,4 +4,13 @@ go 1.25.5
 
 require github.com/julienschmidt/httprouter v1.3.0
 
-require github.com/lib/pq v1.11.2
+require (
+	github.com/BurntSushi/toml v1.6.0
+	github.com/lib/pq v1.11.2
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	golang.org/x/crypto v0.48.0
+	golang.org/x/sys v0.41.0 // indirect
+)
@@ -0,0 +1,82 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/andreshungbz/lab4-database-crud/internal/validator"
+)
+
+// RoomAvailability holds the number of rooms of a room type that can be booked
+// in a hotel for a date range, and the price of the stay.
+type RoomAvailability struct {
+	RoomTypeID     int     `json:"room_type_id"`
+	Title          string  `json:"title"`
+	AvailableRooms int     `json:"available_rooms"`
+	BaseRate       float64 `json:"base_rate"`
+	TotalPrice     float64 `json:"total_price"`
+	MaxOccupancy   int     `json:"max_occupancy"`
+	BedCount       int     `json:"bed_count"`
+	HasBalcony     bool    `json:"has_balcony"`
+}
+
+// ValidateAvailabilitySearch checks the date range and number of guests of an
+// availability search.
+func ValidateAvailabilitySearch(v *validator.Validator, checkin, checkout Date, guests int) {
+	v.Check(!checkin.IsZero(), "checkin", "must be provided")
+	v.Check(!checkout.IsZero(), "checkout", "must be provided")
+	v.Check(checkout.Time().After(checkin.Time()), "checkout", "must be after checkin")
+
+	v.Check(guests > 0, "guests", "must be greater than zero")
+}
+
+// AvailabilityModel holds a handler to the database and the timeout of its queries
+type AvailabilityModel struct {
+	DB      *sql.DB
+	Timeout time.Duration
+}
+
+// Search returns the availability of each room type in a hotel that can hold
+// the number of guests for the date range.
+func (m AvailabilityModel) Search(ctx context.Context, hotelID int64, checkin, checkout Date, guests int) ([]*RoomAvailability, error) {
+	query := `SELECT * FROM fn_get_availability($1, $2, $3, $4)`
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	// retrieves rows from the database
+	rows, err := m.DB.QueryContext(ctx, query, hotelID, checkin, checkout, guests)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	// construct the array of room type availabilities
+	availability := []*RoomAvailability{}
+	for rows.Next() {
+		var ra RoomAvailability
+
+		err := rows.Scan(
+			&ra.RoomTypeID,
+			&ra.Title,
+			&ra.AvailableRooms,
+			&ra.BaseRate,
+			&ra.TotalPrice,
+			&ra.MaxOccupancy,
+			&ra.BedCount,
+			&ra.HasBalcony,
+		)
+		if err != nil {
+			return nil, translateError(err)
+		}
+
+		availability = append(availability, &ra)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, translateError(err)
+	}
+
+	return availability, nil
+}
@@ -0,0 +1,85 @@
+package data
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// DateLayout is the format used for reading and writing dates in JSON and
+// URL query strings.
+const DateLayout = "2006-01-02"
+
+var ErrInvalidDateFormat = errors.New("invalid date format, must be YYYY-MM-DD")
+
+// Date maps a PostgreSQL DATE column. It has no time component and is written
+// to and read from JSON as a "YYYY-MM-DD" string.
+type Date time.Time
+
+// ParseDate converts a "YYYY-MM-DD" string into a Date.
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(DateLayout, s)
+	if err != nil {
+		return Date{}, ErrInvalidDateFormat
+	}
+
+	return Date(t), nil
+}
+
+// Time returns the Date as a time.Time at midnight UTC.
+func (d Date) Time() time.Time {
+	return time.Time(d)
+}
+
+// IsZero reports whether the Date was never set.
+func (d Date) IsZero() bool {
+	return time.Time(d).IsZero()
+}
+
+// String returns the Date in "YYYY-MM-DD" form.
+func (d Date) String() string {
+	return time.Time(d).Format(DateLayout)
+}
+
+// MarshalJSON writes the Date as a quoted "YYYY-MM-DD" string.
+func (d Date) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(d.String())), nil
+}
+
+// UnmarshalJSON reads a quoted "YYYY-MM-DD" string into the Date.
+func (d *Date) UnmarshalJSON(jsonValue []byte) error {
+	unquoted, err := strconv.Unquote(string(jsonValue))
+	if err != nil {
+		return ErrInvalidDateFormat
+	}
+
+	date, err := ParseDate(unquoted)
+	if err != nil {
+		return err
+	}
+
+	*d = date
+	return nil
+}
+
+// Scan implements sql.Scanner so that DATE columns can be read into a Date.
+func (d *Date) Scan(src any) error {
+	switch v := src.(type) {
+	case time.Time:
+		*d = Date(time.Date(v.Year(), v.Month(), v.Day(), 0, 0, 0, 0, time.UTC))
+		return nil
+	case nil:
+		*d = Date{}
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into Date", src)
+	}
+}
+
+// Value implements driver.Valuer so that a Date can be passed as a query
+// argument.
+func (d Date) Value() (driver.Value, error) {
+	return d.String(), nil
+}
@@ -0,0 +1,232 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Department maps the department entity along with the payroll of its
+// currently employed employees. Departments without a budget have no
+// utilization and are never over budget.
+type Department struct {
+	Name              string   `json:"name"`
+	Budget            *float64 `json:"budget"`
+	Headcount         int      `json:"headcount"`
+	TotalSalary       float64  `json:"total_salary"`
+	BudgetUtilization *float64 `json:"budget_utilization"`
+	OverBudget        bool     `json:"over_budget"`
+}
+
+// HotelPayroll is the share of a department's payroll at one hotel. The
+// utilization is the percentage of the department's budget it uses.
+type HotelPayroll struct {
+	HotelID           int64    `json:"hotel_id"`
+	HotelName         string   `json:"hotel_name"`
+	Headcount         int      `json:"headcount"`
+	TotalSalary       float64  `json:"total_salary"`
+	BudgetUtilization *float64 `json:"budget_utilization"`
+	OverBudget        bool     `json:"over_budget"`
+}
+
+// Payroll is a department's payroll broken down by hotel.
+type Payroll struct {
+	Department *Department     `json:"department"`
+	Hotels     []*HotelPayroll `json:"hotels"`
+}
+
+// DepartmentModel holds a handler to the database and the timeout of its queries
+type DepartmentModel struct {
+	DB      *sql.DB
+	Timeout time.Duration
+}
+
+// GetAll reads all departments with their payroll totals.
+func (m DepartmentModel) GetAll(ctx context.Context) ([]*Department, error) {
+	query := `
+		SELECT
+			d.dept_name,
+			d.budget,
+			COUNT(e.id),
+			COALESCE(SUM(e.salary), 0),
+			ROUND(COALESCE(SUM(e.salary), 0) * 100 / NULLIF(d.budget, 0), 2),
+			COALESCE(COALESCE(SUM(e.salary), 0) > d.budget, FALSE)
+		FROM department d
+		LEFT JOIN employee e
+			ON e.department = d.dept_name
+			AND e.employed = TRUE
+		GROUP BY d.dept_name
+		ORDER BY d.dept_name`
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	// retrieves rows from the database
+	rows, err := m.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	// construct the array of departments
+	departments := []*Department{}
+	for rows.Next() {
+		var department Department
+
+		err := rows.Scan(
+			&department.Name,
+			&department.Budget,
+			&department.Headcount,
+			&department.TotalSalary,
+			&department.BudgetUtilization,
+			&department.OverBudget,
+		)
+		if err != nil {
+			return nil, translateError(err)
+		}
+
+		departments = append(departments, &department)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, translateError(err)
+	}
+
+	return departments, nil
+}
+
+// GetPayroll reads a department's name and returns its payroll for every hotel
+// that employs someone in it.
+func (m DepartmentModel) GetPayroll(ctx context.Context, name string) (*Payroll, error) {
+	query := `
+		SELECT
+			d.dept_name,
+			d.budget,
+			COUNT(e.id),
+			COALESCE(SUM(e.salary), 0),
+			ROUND(COALESCE(SUM(e.salary), 0) * 100 / NULLIF(d.budget, 0), 2),
+			COALESCE(COALESCE(SUM(e.salary), 0) > d.budget, FALSE)
+		FROM department d
+		LEFT JOIN employee e
+			ON e.department = d.dept_name
+			AND e.employed = TRUE
+		WHERE d.dept_name = $1
+		GROUP BY d.dept_name`
+
+	var department Department
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, name).Scan(
+		&department.Name,
+		&department.Budget,
+		&department.Headcount,
+		&department.TotalSalary,
+		&department.BudgetUtilization,
+		&department.OverBudget,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, translateError(err)
+		}
+	}
+
+	query = `
+		SELECT
+			h.id,
+			h.name,
+			COUNT(e.id),
+			SUM(e.salary),
+			ROUND(SUM(e.salary) * 100 / NULLIF(d.budget, 0), 2),
+			COALESCE(SUM(e.salary) > d.budget, FALSE)
+		FROM employee e
+		JOIN hotel h ON h.id = e.hotel_id
+		JOIN department d ON d.dept_name = e.department
+		WHERE e.department = $1 AND e.employed = TRUE
+		GROUP BY h.id, d.budget
+		ORDER BY h.id`
+
+	// retrieves rows from the database
+	rows, err := m.DB.QueryContext(ctx, query, name)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	// construct the array of hotel payrolls
+	hotels := []*HotelPayroll{}
+	for rows.Next() {
+		var hotel HotelPayroll
+
+		err := rows.Scan(
+			&hotel.HotelID,
+			&hotel.HotelName,
+			&hotel.Headcount,
+			&hotel.TotalSalary,
+			&hotel.BudgetUtilization,
+			&hotel.OverBudget,
+		)
+		if err != nil {
+			return nil, translateError(err)
+		}
+
+		hotels = append(hotels, &hotel)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, translateError(err)
+	}
+
+	return &Payroll{Department: &department, Hotels: hotels}, nil
+}
+
+// checkDepartmentBudget locks a department for the rest of a transaction and
+// returns ErrOverBudget if giving an employee a salary would push the
+// department's employed payroll over its budget. Unknown departments and
+// departments without a budget are not checked.
+func checkDepartmentBudget(ctx context.Context, tx *sql.Tx, department string, employeeID int64, salary float64) error {
+	query := `
+		SELECT budget
+		FROM department
+		WHERE dept_name = $1
+		FOR UPDATE`
+
+	var budget *float64
+	err := tx.QueryRowContext(ctx, query, department).Scan(&budget)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil
+		default:
+			return translateError(err)
+		}
+	}
+
+	if budget == nil {
+		return nil
+	}
+
+	// the employee's current salary is replaced, not added to
+	query = `
+		SELECT COALESCE(SUM(salary), 0)
+		FROM employee
+		WHERE department = $1 AND employed = TRUE AND id <> $2`
+
+	var total float64
+	err = tx.QueryRowContext(ctx, query, department, employeeID).Scan(&total)
+	if err != nil {
+		return translateError(err)
+	}
+
+	if total+salary > *budget {
+		return ErrOverBudget
+	}
+
+	return nil
+}
@@ -0,0 +1,861 @@
+package data
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/andreshungbz/lab4-database-crud/internal/password"
+	"github.com/andreshungbz/lab4-database-crud/internal/validator"
+)
+
+// AnonymousEmployee represents a client that has not authenticated.
+var AnonymousEmployee = &Employee{}
+
+// EmployeeRoles are the roles an employee can be created with, one for each
+// employee subtype table.
+var EmployeeRoles = []string{RoleOperationsManager, RoleFrontDesk, RoleHousekeeper}
+
+// EmployeeStatuses are the values employees can be filtered by.
+var EmployeeStatuses = []string{"employed", "terminated", "all"}
+
+// Employee maps the employee entity, which is a subtype of the person entity.
+type Employee struct {
+	// employee attributes
+	ID         int64        `json:"id"`
+	HotelID    int64        `json:"hotel_id"`
+	Department string       `json:"department"`
+	ReportsTo  *int64       `json:"reports_to"`
+	Salary     float64      `json:"salary"`
+	SSN        string       `json:"-"`
+	WorkEmail  string       `json:"work_email"`
+	WorkPhone  string       `json:"work_phone"`
+	Password   passwordHash `json:"-"`
+	Employed   bool         `json:"employed"`
+	// subtype attributes
+	Role       string  `json:"role"`
+	Shift      *string `json:"shift,omitempty"`
+	HotelOwner bool    `json:"hotel_owner"`
+	// person attributes
+	Name      string    `json:"name"`
+	Gender    string    `json:"gender"`
+	Street    string    `json:"street"`
+	City      string    `json:"city"`
+	Country   string    `json:"country"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// EmployeeReport is an employee in a reporting tree, holding the employees
+// that report to them.
+type EmployeeReport struct {
+	ID         int64             `json:"id"`
+	Name       string            `json:"name"`
+	Department string            `json:"department"`
+	Role       string            `json:"role"`
+	Employed   bool              `json:"employed"`
+	Depth      int               `json:"depth"`
+	Reports    []*EmployeeReport `json:"reports"`
+}
+
+// EmployeeFilters holds the values that employees can be filtered by. Zero
+// values and empty strings are ignored.
+type EmployeeFilters struct {
+	HotelID    int64
+	Department string
+	Role       string
+	Status     string
+}
+
+// employeeConstraintFields maps the constraints on table employee to the input
+// fields they check.
+var employeeConstraintFields = map[string]string{
+	"employee_hotel_id_fkey":   "hotel_id",
+	"employee_department_fkey": "department",
+	"employee_reports_to_fkey": "reports_to",
+	"employee_ssn_key":         "ssn",
+	"employee_work_email_key":  "work_email",
+	"employee_work_phone_key":  "work_phone",
+}
+
+// IsAnonymous reports whether the Employee is the AnonymousEmployee.
+func (e *Employee) IsAnonymous() bool {
+	return e == AnonymousEmployee
+}
+
+// passwordHash holds an employee's password as stored in employee.password_hash.
+type passwordHash struct {
+	hash []byte
+}
+
+// Set hashes a plaintext password with the current algorithm.
+func (p *passwordHash) Set(plaintextPassword string) error {
+	hash, err := password.Hash(plaintextPassword)
+	if err != nil {
+		return err
+	}
+
+	p.hash = hash
+
+	return nil
+}
+
+// Matches reports whether a plaintext password corresponds to the stored hash,
+// and whether the hash uses a legacy algorithm and should be replaced.
+func (p *passwordHash) Matches(plaintextPassword string) (match bool, needsRehash bool, err error) {
+	return password.Verify(plaintextPassword, p.hash)
+}
+
+// ValidateEmail checks that an email is provided and well-formed.
+func ValidateEmail(v *validator.Validator, email string) {
+	v.Check(email != "", "email", "must be provided")
+	v.Check(validator.Matches(email, validator.EmailRX), "email", "must be a valid email address")
+}
+
+// ValidatePasswordPlaintext checks that a password is provided and within
+// length bounds.
+func ValidatePasswordPlaintext(v *validator.Validator, password string) {
+	v.Check(password != "", "password", "must be provided")
+	v.Check(len(password) >= 8, "password", "must be at least 8 bytes long")
+	v.Check(len(password) <= 72, "password", "must not be more than 72 bytes long")
+}
+
+// ValidatePasswordStrength checks that a new password is long enough and mixes
+// character classes, and that it is not the employee's email. Errors are
+// recorded under key.
+func ValidatePasswordStrength(v *validator.Validator, key, password, email string) {
+	v.Check(password != "", key, "must be provided")
+	v.Check(len(password) >= 12, key, "must be at least 12 bytes long")
+	v.Check(len(password) <= 72, key, "must not be more than 72 bytes long")
+	v.Check(validator.Matches(password, validator.LowercaseRX), key, "must contain a lowercase letter")
+	v.Check(validator.Matches(password, validator.UppercaseRX), key, "must contain an uppercase letter")
+	v.Check(validator.Matches(password, validator.DigitRX), key, "must contain a digit")
+	v.Check(validator.Matches(password, validator.SymbolRX), key, "must contain a symbol")
+	v.Check(!strings.EqualFold(password, email), key, "must not be the same as the email")
+}
+
+// ValidateEmployee checks the employee, subtype, and person details of an
+// employee. Shifts are only held by front desk employees and housekeepers, and
+// only operations managers can be hotel owners.
+func ValidateEmployee(v *validator.Validator, employee *Employee) {
+	v.Check(employee.Name != "", "name", "must be provided")
+	v.Check(len(employee.Name) <= 500, "name", "must not be more than 500 bytes long")
+
+	v.Check(employee.HotelID > 0, "hotel_id", "must be a positive integer")
+	v.Check(employee.Department != "", "department", "must be provided")
+
+	if employee.ReportsTo != nil {
+		v.Check(*employee.ReportsTo > 0, "reports_to", "must be a positive integer")
+		v.Check(*employee.ReportsTo != employee.ID, "reports_to", "must not be the employee themselves")
+	}
+
+	v.Check(employee.Salary > 0, "salary", "must be a positive amount")
+	v.Check(employee.Salary < 10_000_000_000, "salary", "must be less than 10 billion")
+
+	v.Check(employee.SSN != "", "ssn", "must be provided")
+	v.Check(validator.Matches(employee.SSN, validator.SSNRX), "ssn", "must be in the format 123-45-6789")
+
+	v.Check(employee.WorkEmail != "", "work_email", "must be provided")
+	v.Check(validator.Matches(employee.WorkEmail, validator.EmailRX), "work_email", "must be a valid email address")
+	v.Check(employee.WorkPhone != "", "work_phone", "must be provided")
+
+	v.Check(validator.PermittedValue(employee.Role, EmployeeRoles...), "role", "must be one of operations_manager, front_desk, or housekeeper")
+
+	if employee.Role == RoleOperationsManager {
+		v.Check(employee.Shift == nil, "shift", "must not be provided for operations managers")
+	} else {
+		v.Check(employee.Shift != nil, "shift", "must be provided")
+		if employee.Shift != nil {
+			v.Check(validator.PermittedValue(*employee.Shift, ShiftDay, ShiftNight), "shift", "must be one of day or night")
+		}
+		v.Check(!employee.HotelOwner, "hotel_owner", "must only be set for operations managers")
+	}
+}
+
+// ValidateEmployeeFilters checks the employee filter values.
+func ValidateEmployeeFilters(v *validator.Validator, ef EmployeeFilters) {
+	v.Check(ef.HotelID >= 0, "hotel_id", "must not be negative")
+	v.Check(validator.PermittedValue(ef.Status, EmployeeStatuses...), "status", "must be one of employed, terminated, or all")
+
+	if ef.Role != "" {
+		v.Check(validator.PermittedValue(ef.Role, EmployeeRoles...), "role", "must be one of operations_manager, front_desk, or housekeeper")
+	}
+}
+
+// EmployeeConstraintField returns the input field checked by the employee
+// constraint that was violated in err, if any.
+func EmployeeConstraintField(err error) (string, bool) {
+	for constraint, field := range employeeConstraintFields {
+		if strings.HasSuffix(err.Error(), constraint) {
+			return field, true
+		}
+	}
+
+	return "", false
+}
+
+// EmployeeStore stores employees. EmployeeModel stores them in PostgreSQL and
+// MemoryEmployeeStore in memory for handler tests.
+type EmployeeStore interface {
+	GetByEmail(ctx context.Context, email string) (*Employee, error)
+	GetForToken(ctx context.Context, tokenScope, tokenPlaintext string) (*Employee, error)
+	UpdatePassword(ctx context.Context, employee *Employee) error
+	Insert(ctx context.Context, employee *Employee, overrideBudget bool) error
+	Get(ctx context.Context, id int64) (*Employee, error)
+	GetAll(ctx context.Context, ef EmployeeFilters) ([]*Employee, error)
+	Update(ctx context.Context, employee *Employee, overrideBudget bool) error
+	Terminate(ctx context.Context, id int64) error
+	GetReports(ctx context.Context, id int64) ([]*EmployeeReport, error)
+}
+
+// EmployeeModel holds a handler to the database and the timeout of its queries
+type EmployeeModel struct {
+	DB      *sql.DB
+	Timeout time.Duration
+}
+
+// GetByEmail reads an employee's work email and returns an Employee.
+func (m EmployeeModel) GetByEmail(ctx context.Context, email string) (*Employee, error) {
+	query := `
+		SELECT
+			e.id,
+			e.hotel_id,
+			e.department,
+			e.work_email,
+			e.password_hash,
+			e.employed,
+			p.name
+		FROM employee e
+		JOIN person p ON p.id = e.id
+		WHERE e.work_email = $1`
+
+	var employee Employee
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, email).Scan(
+		&employee.ID,
+		&employee.HotelID,
+		&employee.Department,
+		&employee.WorkEmail,
+		&employee.Password.hash,
+		&employee.Employed,
+		&employee.Name,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, translateError(err)
+		}
+	}
+
+	return &employee, nil
+}
+
+// GetForToken returns the currently employed Employee that owns an unexpired
+// token of a scope.
+func (m EmployeeModel) GetForToken(ctx context.Context, tokenScope, tokenPlaintext string) (*Employee, error) {
+	tokenHash := sha256.Sum256([]byte(tokenPlaintext))
+
+	query := `
+		SELECT
+			e.id,
+			e.hotel_id,
+			e.department,
+			e.work_email,
+			e.password_hash,
+			e.employed,
+			p.name
+		FROM employee e
+		JOIN person p ON p.id = e.id
+		JOIN tokens t ON t.employee_id = e.id
+		WHERE t.hash = $1
+			AND t.scope = $2
+			AND t.expiry > $3
+			AND e.employed = TRUE`
+
+	args := []any{tokenHash[:], tokenScope, time.Now()}
+
+	var employee Employee
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(
+		&employee.ID,
+		&employee.HotelID,
+		&employee.Department,
+		&employee.WorkEmail,
+		&employee.Password.hash,
+		&employee.Employed,
+		&employee.Name,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, translateError(err)
+		}
+	}
+
+	return &employee, nil
+}
+
+// UpdatePassword replaces the stored hash of an employee's password.
+func (m EmployeeModel) UpdatePassword(ctx context.Context, employee *Employee) error {
+	query := `
+		UPDATE employee
+		SET password_hash = $1
+		WHERE id = $2`
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, employee.Password.hash, employee.ID)
+	if err != nil {
+		return translateError(err)
+	}
+
+	// No rows being affected means the employee is not in the database
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return translateError(err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// Insert creates a record in tables person, employee, and the subtype table of
+// the employee's role. The employee's salary must fit within their
+// department's budget unless the budget is overridden.
+func (m EmployeeModel) Insert(ctx context.Context, employee *Employee, overrideBudget bool) error {
+	query := `SELECT * FROM fn_create_employee($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)`
+
+	args := []any{
+		employee.HotelID,
+		employee.Department,
+		employee.ReportsTo,
+		employee.Salary,
+		employee.SSN,
+		employee.WorkEmail,
+		employee.WorkPhone,
+		employee.Password.hash,
+		employee.Role,
+		employee.Shift,
+		employee.HotelOwner,
+		employee.Name,
+		employee.Gender,
+		employee.Street,
+		employee.City,
+		employee.Country,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return translateError(err)
+	}
+	defer tx.Rollback()
+
+	if !overrideBudget {
+		err = checkDepartmentBudget(ctx, tx, employee.Department, 0, employee.Salary)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tx.QueryRowContext(ctx, query, args...).Scan(
+		// scan remaining attributes
+		&employee.ID,
+		&employee.CreatedAt,
+	)
+	if err != nil {
+		return translateError(err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return translateError(err)
+	}
+
+	employee.Employed = true
+	return nil
+}
+
+// Get reads an employee's id and returns an Employee.
+func (m EmployeeModel) Get(ctx context.Context, id int64) (*Employee, error) {
+	query := `
+		SELECT
+			e.id,
+			e.hotel_id,
+			e.department,
+			e.reports_to,
+			e.salary,
+			e.ssn,
+			e.work_email,
+			e.work_phone,
+			e.employed,
+			CASE
+				WHEN om.id IS NOT NULL THEN 'operations_manager'
+				WHEN fd.id IS NOT NULL THEN 'front_desk'
+				WHEN hk.id IS NOT NULL THEN 'housekeeper'
+				ELSE ''
+			END,
+			COALESCE(fd.shift, hk.shift)::TEXT,
+			COALESCE(om.hotel_owner, FALSE),
+			p.name,
+			COALESCE(p.gender, ''),
+			COALESCE(p.street, ''),
+			COALESCE(p.city, ''),
+			COALESCE(p.country, ''),
+			p.created_at
+		FROM employee e
+		JOIN person p ON p.id = e.id
+		LEFT JOIN operations_manager om ON om.id = e.id
+		LEFT JOIN front_desk fd ON fd.id = e.id
+		LEFT JOIN housekeeper hk ON hk.id = e.id
+		WHERE e.id = $1`
+
+	var employee Employee
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, id).Scan(
+		&employee.ID,
+		&employee.HotelID,
+		&employee.Department,
+		&employee.ReportsTo,
+		&employee.Salary,
+		&employee.SSN,
+		&employee.WorkEmail,
+		&employee.WorkPhone,
+		&employee.Employed,
+		&employee.Role,
+		&employee.Shift,
+		&employee.HotelOwner,
+		&employee.Name,
+		&employee.Gender,
+		&employee.Street,
+		&employee.City,
+		&employee.Country,
+		&employee.CreatedAt,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, translateError(err)
+		}
+	}
+
+	return &employee, nil
+}
+
+// GetAll reads all employees, filtered by hotel, department, role, and whether
+// they are still employed.
+func (m EmployeeModel) GetAll(ctx context.Context, ef EmployeeFilters) ([]*Employee, error) {
+	query := `
+		SELECT
+			e.id,
+			e.hotel_id,
+			e.department,
+			e.reports_to,
+			e.salary,
+			e.ssn,
+			e.work_email,
+			e.work_phone,
+			e.employed,
+			CASE
+				WHEN om.id IS NOT NULL THEN 'operations_manager'
+				WHEN fd.id IS NOT NULL THEN 'front_desk'
+				WHEN hk.id IS NOT NULL THEN 'housekeeper'
+				ELSE ''
+			END,
+			COALESCE(fd.shift, hk.shift)::TEXT,
+			COALESCE(om.hotel_owner, FALSE),
+			p.name,
+			COALESCE(p.gender, ''),
+			COALESCE(p.street, ''),
+			COALESCE(p.city, ''),
+			COALESCE(p.country, ''),
+			p.created_at
+		FROM employee e
+		JOIN person p ON p.id = e.id
+		LEFT JOIN operations_manager om ON om.id = e.id
+		LEFT JOIN front_desk fd ON fd.id = e.id
+		LEFT JOIN housekeeper hk ON hk.id = e.id
+		WHERE ($1 = 0 OR e.hotel_id = $1)
+			AND ($2 = '' OR e.department = $2)
+			AND ($3 = ''
+				OR ($3 = 'operations_manager' AND om.id IS NOT NULL)
+				OR ($3 = 'front_desk' AND fd.id IS NOT NULL)
+				OR ($3 = 'housekeeper' AND hk.id IS NOT NULL))
+			AND ($4 = 'all'
+				OR ($4 = 'employed' AND e.employed = TRUE)
+				OR ($4 = 'terminated' AND e.employed = FALSE))
+		ORDER BY e.hotel_id, e.department, p.name, e.id`
+
+	args := []any{ef.HotelID, ef.Department, ef.Role, ef.Status}
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	// retrieves rows from the database
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	// construct the array of employees
+	employees := []*Employee{}
+	for rows.Next() {
+		var employee Employee
+
+		err := rows.Scan(
+			&employee.ID,
+			&employee.HotelID,
+			&employee.Department,
+			&employee.ReportsTo,
+			&employee.Salary,
+			&employee.SSN,
+			&employee.WorkEmail,
+			&employee.WorkPhone,
+			&employee.Employed,
+			&employee.Role,
+			&employee.Shift,
+			&employee.HotelOwner,
+			&employee.Name,
+			&employee.Gender,
+			&employee.Street,
+			&employee.City,
+			&employee.Country,
+			&employee.CreatedAt,
+		)
+		if err != nil {
+			return nil, translateError(err)
+		}
+
+		employees = append(employees, &employee)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, translateError(err)
+	}
+
+	return employees, nil
+}
+
+// Update changes the employee, subtype, and person details of an employee. An
+// employee cannot report to someone who reports to them, and a raise or
+// department transfer of an employed employee must fit within the department's
+// budget unless the budget is overridden.
+func (m EmployeeModel) Update(ctx context.Context, employee *Employee, overrideBudget bool) error {
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return translateError(err)
+	}
+	defer tx.Rollback()
+
+	// walk up the chain of the new manager looking for the employee
+	if employee.ReportsTo != nil {
+		query := `
+			WITH RECURSIVE chain AS (
+				SELECT e.id, e.reports_to
+				FROM employee e
+				WHERE e.id = $1
+				UNION
+				SELECT e.id, e.reports_to
+				FROM employee e
+				JOIN chain c ON e.id = c.reports_to
+			)
+			SELECT EXISTS (SELECT 1 FROM chain WHERE id = $2)`
+
+		var cycle bool
+		err = tx.QueryRowContext(ctx, query, *employee.ReportsTo, employee.ID).Scan(&cycle)
+		if err != nil {
+			return translateError(err)
+		}
+
+		if cycle {
+			return ErrReportingCycle
+		}
+	}
+
+	// lock the stored employee to compare the department and salary against
+	query := `
+		SELECT department, salary
+		FROM employee
+		WHERE id = $1
+		FOR UPDATE`
+
+	var storedDepartment string
+	var storedSalary float64
+
+	err = tx.QueryRowContext(ctx, query, employee.ID).Scan(&storedDepartment, &storedSalary)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrRecordNotFound
+		default:
+			return translateError(err)
+		}
+	}
+
+	// only changes that increase the department's payroll are checked, so
+	// departments already over budget can still have other details updated
+	increase := employee.Department != storedDepartment || employee.Salary > storedSalary
+
+	if employee.Employed && increase && !overrideBudget {
+		err = checkDepartmentBudget(ctx, tx, employee.Department, employee.ID, employee.Salary)
+		if err != nil {
+			return err
+		}
+	}
+
+	query = `
+		UPDATE employee
+		SET hotel_id = $1, department = $2, reports_to = $3, salary = $4, ssn = $5, work_email = $6, work_phone = $7
+		WHERE id = $8`
+
+	args := []any{
+		employee.HotelID,
+		employee.Department,
+		employee.ReportsTo,
+		employee.Salary,
+		employee.SSN,
+		employee.WorkEmail,
+		employee.WorkPhone,
+		employee.ID,
+	}
+
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return translateError(err)
+	}
+
+	// No rows being affected means the employee is not in the database
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return translateError(err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	query = `
+		UPDATE person
+		SET name = $1, gender = $2, street = $3, city = $4, country = $5
+		WHERE id = $6`
+
+	args = []any{
+		employee.Name,
+		employee.Gender,
+		employee.Street,
+		employee.City,
+		employee.Country,
+		employee.ID,
+	}
+
+	_, err = tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return translateError(err)
+	}
+
+	// update the subtype attributes of the employee's role
+	switch employee.Role {
+	case RoleOperationsManager:
+		query = `UPDATE operations_manager SET hotel_owner = $1 WHERE id = $2`
+		_, err = tx.ExecContext(ctx, query, employee.HotelOwner, employee.ID)
+	case RoleFrontDesk:
+		query = `UPDATE front_desk SET shift = $1 WHERE id = $2`
+		_, err = tx.ExecContext(ctx, query, employee.Shift, employee.ID)
+	case RoleHousekeeper:
+		query = `UPDATE housekeeper SET shift = $1 WHERE id = $2`
+		_, err = tx.ExecContext(ctx, query, employee.Shift, employee.ID)
+	}
+	if err != nil {
+		return translateError(err)
+	}
+
+	return tx.Commit()
+}
+
+// Terminate marks an employee as no longer employed. Their authentication
+// tokens are removed and their open housekeeping tasks become unassigned. The
+// employee's records are kept for history.
+func (m EmployeeModel) Terminate(ctx context.Context, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return translateError(err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT employed
+		FROM employee
+		WHERE id = $1
+		FOR UPDATE`
+
+	var employed bool
+	err = tx.QueryRowContext(ctx, query, id).Scan(&employed)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrRecordNotFound
+		default:
+			return translateError(err)
+		}
+	}
+
+	if !employed {
+		return ErrEmployeeTerminated
+	}
+
+	query = `UPDATE employee SET employed = FALSE WHERE id = $1`
+	_, err = tx.ExecContext(ctx, query, id)
+	if err != nil {
+		return translateError(err)
+	}
+
+	query = `DELETE FROM tokens WHERE employee_id = $1`
+	_, err = tx.ExecContext(ctx, query, id)
+	if err != nil {
+		return translateError(err)
+	}
+
+	query = `
+		UPDATE housekeeping_task
+		SET housekeeper_id = NULL
+		WHERE housekeeper_id = $1 AND completed_at IS NULL`
+
+	_, err = tx.ExecContext(ctx, query, id)
+	if err != nil {
+		return translateError(err)
+	}
+
+	return tx.Commit()
+}
+
+// GetReports returns the tree of employees that report to an employee,
+// directly or indirectly.
+func (m EmployeeModel) GetReports(ctx context.Context, id int64) ([]*EmployeeReport, error) {
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	// distinguish a missing employee from an employee without reports
+	query := `SELECT EXISTS (SELECT 1 FROM employee WHERE id = $1)`
+
+	var exists bool
+	err := m.DB.QueryRowContext(ctx, query, id).Scan(&exists)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	if !exists {
+		return nil, ErrRecordNotFound
+	}
+
+	// the path guards against cycles in reports_to
+	query = `
+		WITH RECURSIVE reports AS (
+			SELECT e.id, e.reports_to, 1 AS depth, ARRAY[e.reports_to, e.id] AS path
+			FROM employee e
+			WHERE e.reports_to = $1
+			UNION ALL
+			SELECT e.id, e.reports_to, r.depth + 1, r.path || e.id
+			FROM employee e
+			JOIN reports r ON e.reports_to = r.id
+			WHERE NOT e.id = ANY(r.path)
+		)
+		SELECT
+			r.id,
+			r.reports_to,
+			r.depth,
+			p.name,
+			e.department,
+			CASE
+				WHEN om.id IS NOT NULL THEN 'operations_manager'
+				WHEN fd.id IS NOT NULL THEN 'front_desk'
+				WHEN hk.id IS NOT NULL THEN 'housekeeper'
+				ELSE ''
+			END,
+			e.employed
+		FROM reports r
+		JOIN employee e ON e.id = r.id
+		JOIN person p ON p.id = r.id
+		LEFT JOIN operations_manager om ON om.id = r.id
+		LEFT JOIN front_desk fd ON fd.id = r.id
+		LEFT JOIN housekeeper hk ON hk.id = r.id
+		ORDER BY r.depth, p.name, r.id`
+
+	// retrieves rows from the database
+	rows, err := m.DB.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	// construct the tree of reports, where parents are read before their
+	// reports because rows are ordered by depth
+	reports := []*EmployeeReport{}
+	nodes := make(map[int64]*EmployeeReport)
+	for rows.Next() {
+		var report EmployeeReport
+		var reportsTo int64
+
+		err := rows.Scan(
+			&report.ID,
+			&reportsTo,
+			&report.Depth,
+			&report.Name,
+			&report.Department,
+			&report.Role,
+			&report.Employed,
+		)
+		if err != nil {
+			return nil, translateError(err)
+		}
+
+		report.Reports = []*EmployeeReport{}
+		nodes[report.ID] = &report
+
+		if parent, ok := nodes[reportsTo]; ok {
+			parent.Reports = append(parent.Reports, &report)
+		} else {
+			reports = append(reports, &report)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, translateError(err)
+	}
+
+	return reports, nil
+}
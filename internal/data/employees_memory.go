@@ -0,0 +1,359 @@
+package data
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// MemoryEmployeeStore stores employees and their tokens in memory. It returns
+// the same errors as the employee constraints and EmployeeModel so handlers
+// can be tested without PostgreSQL. Hotels and departments are not stored, so
+// their references and department budgets are not checked.
+type MemoryEmployeeStore struct {
+	mu        sync.Mutex
+	nextID    int64
+	employees map[int64]*Employee // keyed by id
+	tokens    map[string]*Token   // keyed by hash
+}
+
+var _ EmployeeStore = (*MemoryEmployeeStore)(nil)
+
+// NewMemoryEmployeeStore returns an empty MemoryEmployeeStore.
+func NewMemoryEmployeeStore() *MemoryEmployeeStore {
+	return &MemoryEmployeeStore{
+		nextID:    1,
+		employees: make(map[int64]*Employee),
+		tokens:    make(map[string]*Token),
+	}
+}
+
+// NewToken generates a token for an employee and stores it, as TokenModel.New
+// does in PostgreSQL.
+func (s *MemoryEmployeeStore) NewToken(employeeID int64, ttl time.Duration, scope string) *Token {
+	token := generateToken(employeeID, ttl, scope)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tokens[string(token.Hash)] = token
+
+	return token
+}
+
+// GetByEmail returns a copy of the employee with a work email.
+func (s *MemoryEmployeeStore) GetByEmail(ctx context.Context, email string) (*Employee, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, translateError(err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, stored := range s.employees {
+		if stored.WorkEmail == email {
+			employee := *stored
+			return &employee, nil
+		}
+	}
+
+	return nil, ErrRecordNotFound
+}
+
+// GetForToken returns a copy of the currently employed employee that owns an
+// unexpired token of a scope.
+func (s *MemoryEmployeeStore) GetForToken(ctx context.Context, tokenScope, tokenPlaintext string) (*Employee, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, translateError(err)
+	}
+
+	tokenHash := sha256.Sum256([]byte(tokenPlaintext))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.tokens[string(tokenHash[:])]
+	if !ok || token.Scope != tokenScope || !token.Expiry.After(time.Now()) {
+		return nil, ErrRecordNotFound
+	}
+
+	stored, ok := s.employees[token.EmployeeID]
+	if !ok || !stored.Employed {
+		return nil, ErrRecordNotFound
+	}
+
+	employee := *stored
+	return &employee, nil
+}
+
+// UpdatePassword replaces the stored hash of an employee's password.
+func (s *MemoryEmployeeStore) UpdatePassword(ctx context.Context, employee *Employee) error {
+	if err := ctx.Err(); err != nil {
+		return translateError(err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.employees[employee.ID]
+	if !ok {
+		return ErrRecordNotFound
+	}
+
+	stored.Password = employee.Password
+	return nil
+}
+
+// Insert stores a copy of an employee. Unique and reports_to constraint
+// violations return the errors of their constraints.
+func (s *MemoryEmployeeStore) Insert(ctx context.Context, employee *Employee, overrideBudget bool) error {
+	if err := ctx.Err(); err != nil {
+		return translateError(err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.checkConstraints(employee)
+	if err != nil {
+		return err
+	}
+
+	// assign the attributes set by the database
+	employee.ID = s.nextID
+	employee.CreatedAt = time.Now().Truncate(time.Second)
+	employee.Employed = true
+	s.nextID++
+
+	stored := *employee
+	s.employees[employee.ID] = &stored
+
+	return nil
+}
+
+// checkConstraints returns the error of the first employee constraint an
+// employee would violate, ignoring the employee's own stored record.
+func (s *MemoryEmployeeStore) checkConstraints(employee *Employee) error {
+	for _, stored := range s.employees {
+		if stored.ID == employee.ID {
+			continue
+		}
+
+		switch {
+		case stored.SSN == employee.SSN:
+			return translateError(&pq.Error{Code: "23505", Constraint: "employee_ssn_key"})
+		case stored.WorkEmail == employee.WorkEmail:
+			return translateError(&pq.Error{Code: "23505", Constraint: "employee_work_email_key"})
+		case stored.WorkPhone == employee.WorkPhone:
+			return translateError(&pq.Error{Code: "23505", Constraint: "employee_work_phone_key"})
+		}
+	}
+
+	if employee.ReportsTo != nil {
+		if _, ok := s.employees[*employee.ReportsTo]; !ok {
+			return translateError(&pq.Error{Code: "23503", Constraint: "employee_reports_to_fkey"})
+		}
+	}
+
+	return nil
+}
+
+// Get returns a copy of the employee with an id.
+func (s *MemoryEmployeeStore) Get(ctx context.Context, id int64) (*Employee, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, translateError(err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.employees[id]
+	if !ok {
+		return nil, ErrRecordNotFound
+	}
+
+	employee := *stored
+	return &employee, nil
+}
+
+// GetAll returns copies of the employees, filtered and ordered like
+// EmployeeModel.GetAll. Names are compared byte-wise rather than by collation.
+func (s *MemoryEmployeeStore) GetAll(ctx context.Context, ef EmployeeFilters) ([]*Employee, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, translateError(err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	employees := []*Employee{}
+	for _, stored := range s.employees {
+		if !ef.matches(stored) {
+			continue
+		}
+
+		employee := *stored
+		employees = append(employees, &employee)
+	}
+
+	slices.SortFunc(employees, func(a, b *Employee) int {
+		return cmp.Or(
+			cmp.Compare(a.HotelID, b.HotelID),
+			strings.Compare(a.Department, b.Department),
+			strings.Compare(a.Name, b.Name),
+			cmp.Compare(a.ID, b.ID),
+		)
+	})
+
+	return employees, nil
+}
+
+// matches reports whether an employee satisfies the filters, as in the WHERE
+// clause of EmployeeModel.GetAll.
+func (ef EmployeeFilters) matches(employee *Employee) bool {
+	switch {
+	case ef.HotelID != 0 && employee.HotelID != ef.HotelID:
+		return false
+	case ef.Department != "" && employee.Department != ef.Department:
+		return false
+	case ef.Role != "" && employee.Role != ef.Role:
+		return false
+	case ef.Status == "employed" && !employee.Employed:
+		return false
+	case ef.Status == "terminated" && employee.Employed:
+		return false
+	}
+
+	return true
+}
+
+// Update replaces the details of a stored employee. The role, employment,
+// password, and creation time are kept, and an employee cannot report to
+// someone who reports to them.
+func (s *MemoryEmployeeStore) Update(ctx context.Context, employee *Employee, overrideBudget bool) error {
+	if err := ctx.Err(); err != nil {
+		return translateError(err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.employees[employee.ID]
+	if !ok {
+		return ErrRecordNotFound
+	}
+
+	// walk up the chain of the new manager looking for the employee
+	visited := make(map[int64]bool)
+	for id := employee.ReportsTo; id != nil && !visited[*id]; {
+		if *id == employee.ID {
+			return ErrReportingCycle
+		}
+		visited[*id] = true
+
+		manager, ok := s.employees[*id]
+		if !ok {
+			break
+		}
+		id = manager.ReportsTo
+	}
+
+	err := s.checkConstraints(employee)
+	if err != nil {
+		return err
+	}
+
+	updated := *employee
+	updated.Role = stored.Role
+	updated.Employed = stored.Employed
+	updated.Password = stored.Password
+	updated.CreatedAt = stored.CreatedAt
+	s.employees[employee.ID] = &updated
+
+	return nil
+}
+
+// Terminate marks an employee as no longer employed and removes their tokens.
+func (s *MemoryEmployeeStore) Terminate(ctx context.Context, id int64) error {
+	if err := ctx.Err(); err != nil {
+		return translateError(err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.employees[id]
+	if !ok {
+		return ErrRecordNotFound
+	}
+
+	if !stored.Employed {
+		return ErrEmployeeTerminated
+	}
+
+	stored.Employed = false
+
+	for hash, token := range s.tokens {
+		if token.EmployeeID == id {
+			delete(s.tokens, hash)
+		}
+	}
+
+	return nil
+}
+
+// GetReports returns the tree of employees that report to an employee,
+// directly or indirectly, ordered like EmployeeModel.GetReports.
+func (s *MemoryEmployeeStore) GetReports(ctx context.Context, id int64) ([]*EmployeeReport, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, translateError(err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.employees[id]; !ok {
+		return nil, ErrRecordNotFound
+	}
+
+	// the path guards against cycles in reports_to
+	return s.reportsOf(id, 1, map[int64]bool{id: true}), nil
+}
+
+// reportsOf returns the reports of an employee at a depth of the tree, along
+// with their own reports.
+func (s *MemoryEmployeeStore) reportsOf(id int64, depth int, path map[int64]bool) []*EmployeeReport {
+	reports := []*EmployeeReport{}
+	for _, stored := range s.employees {
+		if stored.ReportsTo == nil || *stored.ReportsTo != id || path[stored.ID] {
+			continue
+		}
+
+		reports = append(reports, &EmployeeReport{
+			ID:         stored.ID,
+			Name:       stored.Name,
+			Department: stored.Department,
+			Role:       stored.Role,
+			Employed:   stored.Employed,
+			Depth:      depth,
+		})
+	}
+
+	slices.SortFunc(reports, func(a, b *EmployeeReport) int {
+		return cmp.Or(strings.Compare(a.Name, b.Name), cmp.Compare(a.ID, b.ID))
+	})
+
+	for _, report := range reports {
+		path[report.ID] = true
+		report.Reports = s.reportsOf(report.ID, depth+1, path)
+		delete(path, report.ID)
+	}
+
+	return reports
+}
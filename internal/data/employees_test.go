@@ -0,0 +1,57 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/andreshungbz/lab4-database-crud/internal/validator"
+)
+
+func TestValidateEmployeeRole(t *testing.T) {
+	day := ShiftDay
+	evening := "evening"
+
+	tests := []struct {
+		name       string
+		role       string
+		shift      *string
+		hotelOwner bool
+		wantKey    string
+	}{
+		{"manager", RoleOperationsManager, nil, true, ""},
+		{"manager with shift", RoleOperationsManager, &day, false, "shift"},
+		{"front desk", RoleFrontDesk, &day, false, ""},
+		{"front desk without shift", RoleFrontDesk, nil, false, "shift"},
+		{"housekeeper with unknown shift", RoleHousekeeper, &evening, false, "shift"},
+		{"housekeeper owner", RoleHousekeeper, &day, true, "hotel_owner"},
+		{"unknown role", "chef", &day, false, "role"},
+	}
+
+	for _, tt := range tests {
+		employee := &Employee{
+			HotelID:    1,
+			Department: "Hotel Operations",
+			Salary:     50000,
+			SSN:        "123-45-6789",
+			WorkEmail:  "alice@example.com",
+			WorkPhone:  "501-000-0000",
+			Role:       tt.role,
+			Shift:      tt.shift,
+			HotelOwner: tt.hotelOwner,
+			Name:       "Alice",
+		}
+
+		v := validator.New()
+		ValidateEmployee(v, employee)
+
+		if tt.wantKey == "" {
+			if !v.Valid() {
+				t.Errorf("%s: expected valid, got %v", tt.name, v.Errors)
+			}
+			continue
+		}
+
+		if _, ok := v.Errors[tt.wantKey]; !ok || len(v.Errors) != 1 {
+			t.Errorf("%s: expected a single error for %q, got %v", tt.name, tt.wantKey, v.Errors)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// Errors translated from the tagged exceptions raised by the PL/pgSQL functions.
+var (
+	ErrGuestNotFound            = errors.New("guest not found")
+	ErrNoAvailableRoom          = errors.New("no available room")
+	ErrReservationGuestMismatch = errors.New("reservation does not belong to guest")
+	ErrNonexistentRoomType      = errors.New("nonexistent room type")
+	ErrHotelNotFound            = errors.New("hotel not found")
+	ErrReservationNotFound      = errors.New("reservation not found")
+	ErrReservationCanceled      = errors.New("reservation canceled")
+	ErrReservationCompleted     = errors.New("reservation completed")
+	ErrReservationCheckedIn     = errors.New("reservation already checked in")
+	ErrReservationNotCheckedIn  = errors.New("reservation not checked in")
+	ErrReservationNoShow        = errors.New("reservation no-show")
+	ErrOutsideStayDates         = errors.New("outside stay dates")
+	ErrRoomNotReady             = errors.New("room not ready")
+	ErrRoomOutOfOrder           = errors.New("room out of order")
+)
+
+// Errors returned when enforcing business rules in Go.
+var (
+	ErrRoomNotFound            = errors.New("room not found")
+	ErrIllegalStatusTransition = errors.New("illegal room status transition")
+	ErrOutstandingTasks        = errors.New("outstanding housekeeping tasks")
+	ErrTaskCompleted           = errors.New("housekeeping task completed")
+	ErrHousekeeperNotFound     = errors.New("housekeeper not found")
+	ErrWrongShift              = errors.New("housekeeper not on shift")
+	ErrReportResolved          = errors.New("maintenance report resolved")
+	ErrReportNotResolved       = errors.New("maintenance report not resolved")
+	ErrTechnicianNotFound      = errors.New("technician not found")
+	ErrEmployeeTerminated      = errors.New("employee terminated")
+	ErrReportingCycle          = errors.New("reporting cycle")
+	ErrOverBudget              = errors.New("department over budget")
+)
+
+// Errors translated from PostgreSQL integrity constraint violations.
+var (
+	ErrDuplicatePassport = errors.New("duplicate passport number")
+	ErrDuplicateRecord   = errors.New("duplicate record")
+	ErrInvalidReference  = errors.New("invalid reference")
+	ErrCheckViolation    = errors.New("check constraint violation")
+)
+
+// ErrQueryCanceled is returned when a query's context is canceled or its timeout
+// expires before the query completes.
+var ErrQueryCanceled = errors.New("query canceled")
+
+// exceptionTags maps the bracketed tags that prefix the messages of exceptions
+// raised by the PL/pgSQL functions to their errors.
+var exceptionTags = map[string]error{
+	"guest-not-found":            ErrGuestNotFound,
+	"no-available-room":          ErrNoAvailableRoom,
+	"reservation-guest-mismatch": ErrReservationGuestMismatch,
+	"nonexistent-room-type":      ErrNonexistentRoomType,
+	"edit-conflict":              ErrEditConflict,
+	"hotel-not-found":            ErrHotelNotFound,
+	"reservation-not-found":      ErrReservationNotFound,
+	"reservation-canceled":       ErrReservationCanceled,
+	"reservation-completed":      ErrReservationCompleted,
+	"reservation-checked-in":     ErrReservationCheckedIn,
+	"reservation-not-checked-in": ErrReservationNotCheckedIn,
+	"reservation-no-show":        ErrReservationNoShow,
+	"outside-stay-dates":         ErrOutsideStayDates,
+	"room-not-ready":             ErrRoomNotReady,
+	"room-out-of-order":          ErrRoomOutOfOrder,
+}
+
+// namedConstraints maps the constraints whose violations have errors more
+// specific than those of constraintViolations.
+var namedConstraints = map[string]error{
+	"guest_passport_number_key": ErrDuplicatePassport,
+}
+
+// constraintViolations maps PostgreSQL integrity constraint violation condition
+// names to their errors.
+var constraintViolations = map[string]error{
+	"unique_violation":      ErrDuplicateRecord,
+	"foreign_key_violation": ErrInvalidReference,
+	"check_violation":       ErrCheckViolation,
+}
+
+// translateError converts a *pq.Error raised by a tagged exception or a
+// constraint violation into one of the errors above, wrapped with the
+// original message. Queries cut short by their context are wrapped with
+// ErrQueryCanceled. Any other error is returned unchanged.
+func translateError(err error) error {
+	if errors.Is(err, ErrQueryCanceled) {
+		return err
+	}
+
+	// database/sql returns the context's error if it ended before the query was
+	// sent, otherwise PostgreSQL cancels the running query
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", ErrQueryCanceled, err)
+	}
+
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+
+	if pqErr.Code.Name() == "query_canceled" {
+		return fmt.Errorf("%w: %w", ErrQueryCanceled, err)
+	}
+
+	// tagged exceptions use the raise_exception condition
+	if pqErr.Code.Name() == "raise_exception" {
+		tag, message := splitExceptionTag(pqErr.Message)
+		if tagErr, ok := exceptionTags[tag]; ok {
+			return fmt.Errorf("%w: %s", tagErr, message)
+		}
+		return err
+	}
+
+	if violationErr, ok := constraintViolations[pqErr.Code.Name()]; ok {
+		// the more specific error of a constraint also wraps the general one
+		if constraintErr, ok := namedConstraints[pqErr.Constraint]; ok {
+			return fmt.Errorf("%w: %w: %s", constraintErr, violationErr, pqErr.Constraint)
+		}
+		return fmt.Errorf("%w: %s", violationErr, pqErr.Constraint)
+	}
+
+	return err
+}
+
+// splitExceptionTag separates a message such as "[guest-not-found] Guest with
+// passport X does not exist" into its tag and the remaining message. The tag
+// is empty if the message does not begin with one.
+func splitExceptionTag(message string) (string, string) {
+	if !strings.HasPrefix(message, "[") {
+		return "", message
+	}
+
+	end := strings.Index(message, "]")
+	if end == -1 {
+		return "", message
+	}
+
+	return message[1:end], strings.TrimSpace(message[end+1:])
+}
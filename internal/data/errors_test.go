@@ -0,0 +1,85 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestTranslateError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{
+			name: "guest not found tag",
+			err:  &pq.Error{Code: "P0001", Message: "[guest-not-found] Guest with passport X does not exist"},
+			want: ErrGuestNotFound,
+		},
+		{
+			name: "no available room tag",
+			err:  &pq.Error{Code: "P0001", Message: "[no-available-room] No available room of type 1 for requested dates"},
+			want: ErrNoAvailableRoom,
+		},
+		{
+			name: "reservation no-show tag",
+			err:  &pq.Error{Code: "P0001", Message: "[reservation-no-show] Reservation 1 ended on 2025-01-03 without being checked in"},
+			want: ErrReservationNoShow,
+		},
+		{
+			name: "unique violation",
+			err:  &pq.Error{Code: "23505", Constraint: "guest_passport_number_key"},
+			want: ErrDuplicateRecord,
+		},
+		{
+			name: "duplicate passport",
+			err:  &pq.Error{Code: "23505", Constraint: "guest_passport_number_key"},
+			want: ErrDuplicatePassport,
+		},
+		{
+			name: "foreign key violation",
+			err:  &pq.Error{Code: "23503", Constraint: "registration_hotel_id_room_number_fkey"},
+			want: ErrInvalidReference,
+		},
+		{
+			name: "check violation",
+			err:  &pq.Error{Code: "23514", Constraint: "reservation_check"},
+			want: ErrCheckViolation,
+		},
+		{
+			name: "canceled query",
+			err:  &pq.Error{Code: "57014", Message: "canceling statement due to user request"},
+			want: ErrQueryCanceled,
+		},
+		{
+			name: "context deadline",
+			err:  fmt.Errorf("begin: %w", context.DeadlineExceeded),
+			want: ErrQueryCanceled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := translateError(tt.err); !errors.Is(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTranslateErrorUnchanged(t *testing.T) {
+	// untagged exceptions and other errors are passed through
+	untagged := &pq.Error{Code: "P0001", Message: "something else"}
+	if got := translateError(untagged); got != error(untagged) {
+		t.Errorf("expected untagged exception to be unchanged, got %v", got)
+	}
+
+	other := errors.New("other")
+	if got := translateError(other); got != other {
+		t.Errorf("expected error to be unchanged, got %v", got)
+	}
+}
@@ -0,0 +1,136 @@
+package data
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/andreshungbz/lab4-database-crud/internal/validator"
+)
+
+// Filters holds the pagination and sorting values for listing records.
+// Sort is one of the values in SortSafelist, optionally prefixed with - for
+// descending order. If Cursor is set, keyset pagination is used and Page is
+// ignored.
+type Filters struct {
+	Page         int
+	PageSize     int
+	Cursor       string
+	Sort         string
+	SortSafelist []string
+}
+
+// ValidateFilters checks the pagination and sorting values.
+func ValidateFilters(v *validator.Validator, f Filters) {
+	v.Check(f.Page > 0, "page", "must be greater than zero")
+	v.Check(f.Page <= 10_000_000, "page", "must be a maximum of 10 million")
+	v.Check(f.PageSize > 0, "page_size", "must be greater than zero")
+	v.Check(f.PageSize <= 100, "page_size", "must be a maximum of 100")
+
+	v.Check(validator.PermittedValue(f.Sort, f.SortSafelist...), "sort", "invalid sort value")
+
+	if f.Cursor != "" {
+		// a cursor only continues pages with the sort it was created for
+		c, err := decodeCursor(f.Cursor)
+		v.Check(err == nil && c.Sort == f.Sort, "cursor", "invalid cursor value")
+	}
+}
+
+// sortColumn returns the sort value without the descending prefix. It panics
+// if the sort value is not in the safelist, guarding against SQL injection.
+func (f Filters) sortColumn() string {
+	if slices.Contains(f.SortSafelist, f.Sort) {
+		return strings.TrimPrefix(f.Sort, "-")
+	}
+
+	panic("unsafe sort parameter: " + f.Sort)
+}
+
+// sortDirection returns the SQL sort direction.
+func (f Filters) sortDirection() string {
+	if strings.HasPrefix(f.Sort, "-") {
+		return "DESC"
+	}
+
+	return "ASC"
+}
+
+// keysetOperator returns the comparison operator used to select the rows
+// after a cursor for the sort direction.
+func (f Filters) keysetOperator() string {
+	if f.sortDirection() == "DESC" {
+		return "<"
+	}
+
+	return ">"
+}
+
+// limit returns the number of records in a page.
+func (f Filters) limit() int {
+	return f.PageSize
+}
+
+// offset returns the number of records to skip for the page. Cursor
+// pagination never skips records.
+func (f Filters) offset() int {
+	if f.Cursor != "" {
+		return 0
+	}
+
+	return (f.Page - 1) * f.PageSize
+}
+
+// Metadata holds the pagination information returned alongside listed records.
+type Metadata struct {
+	CurrentPage  int    `json:"current_page,omitempty"`
+	PageSize     int    `json:"page_size,omitempty"`
+	FirstPage    int    `json:"first_page,omitempty"`
+	LastPage     int    `json:"last_page,omitempty"`
+	TotalRecords int    `json:"total_records,omitempty"`
+	NextCursor   string `json:"next_cursor,omitempty"`
+}
+
+// calculateMetadata returns the Metadata for page-based pagination.
+func calculateMetadata(totalRecords, page, pageSize int) Metadata {
+	if totalRecords == 0 {
+		return Metadata{}
+	}
+
+	return Metadata{
+		CurrentPage:  page,
+		PageSize:     pageSize,
+		FirstPage:    1,
+		LastPage:     int(math.Ceil(float64(totalRecords) / float64(pageSize))),
+		TotalRecords: totalRecords,
+	}
+}
+
+// cursor identifies the last record of a page for keyset pagination. Value is
+// the record's sort column value, Key is its unique tie-breaker, and Sort is
+// the sort value of the page.
+type cursor struct {
+	Value string `json:"v"`
+	Key   string `json:"k"`
+	Sort  string `json:"s"`
+}
+
+// encodeCursor returns an opaque string for a cursor.
+func encodeCursor(c cursor) string {
+	js, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(js)
+}
+
+// decodeCursor reads a string created by encodeCursor.
+func decodeCursor(s string) (cursor, error) {
+	var c cursor
+
+	js, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, err
+	}
+
+	err = json.Unmarshal(js, &c)
+	return c, err
+}
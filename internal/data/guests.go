@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"regexp"
 	"time"
 
 	"github.com/andreshungbz/lab4-database-crud/internal/validator"
@@ -23,21 +25,90 @@ type Guest struct {
 	City      string    `json:"city"`
 	Country   string    `json:"country"`
 	CreatedAt time.Time `json:"-"`
+	Version   int32     `json:"version"`
 }
 
-// ValidateGuest checks for the passport number.
+// GuestGenders are the values a guest's gender can be, if provided.
+var GuestGenders = []string{"M", "F", "X"}
+
+// passportFormats maps the ISO 3166-1 alpha-2 codes of issuing countries to
+// the format of their passport numbers. Other countries use
+// defaultPassportFormat, the document number format of ICAO 9303.
+var passportFormats = map[string]*regexp.Regexp{
+	"AU": regexp.MustCompile(`^[A-Z]{1,2}[0-9]{7}$`),
+	"BZ": regexp.MustCompile(`^[A-Z][0-9]{7}$`),
+	"CA": regexp.MustCompile(`^[A-Z]{2}[0-9]{6}$`),
+	"DE": regexp.MustCompile(`^[CFGHJKLMNPRTVWXYZ0-9]{9}$`),
+	"ES": regexp.MustCompile(`^[A-Z]{3}[0-9]{6}$`),
+	"FR": regexp.MustCompile(`^[0-9]{2}[A-Z]{2}[0-9]{5}$`),
+	"GB": regexp.MustCompile(`^[0-9]{9}$`),
+	"IN": regexp.MustCompile(`^[A-Z][0-9]{7}$`),
+	"IT": regexp.MustCompile(`^[A-Z]{2}[0-9]{7}$`),
+	"JP": regexp.MustCompile(`^[A-Z]{2}[0-9]{7}$`),
+	"MX": regexp.MustCompile(`^[A-Z][0-9]{8}$`),
+	"US": regexp.MustCompile(`^[A-Z0-9][0-9]{8}$`),
+}
+
+var defaultPassportFormat = regexp.MustCompile(`^[A-Z0-9]{6,9}$`)
+
+// ValidateGuest checks the contact, person, and passport details of a guest.
+// Passport numbers must match the format of the guest's country when it is
+// known. Gender, street, city, and country are optional.
 func ValidateGuest(v *validator.Validator, guest *Guest) {
+	v.Check(guest.ContactEmail != "", "contact_email", "must be provided")
+	v.Check(len(guest.ContactEmail) <= 254, "contact_email", "must not be more than 254 bytes long")
+	v.Check(validator.Matches(guest.ContactEmail, validator.EmailRX), "contact_email", "must be a valid email address")
+
+	v.Check(guest.ContactPhone != "", "contact_phone", "must be provided")
+	v.Check(validator.Matches(guest.ContactPhone, validator.PhoneRX), "contact_phone", "must be a phone number of 7 to 15 digits with an optional leading +")
+
+	v.Check(guest.Name != "", "name", "must be provided")
+	v.Check(len(guest.Name) <= 500, "name", "must not be more than 500 bytes long")
+
+	if guest.Gender != "" {
+		v.Check(validator.PermittedValue(guest.Gender, GuestGenders...), "gender", "must be one of M, F, or X")
+	}
+
+	v.Check(len(guest.Street) <= 200, "street", "must not be more than 200 bytes long")
+	v.Check(len(guest.City) <= 100, "city", "must not be more than 100 bytes long")
+
+	// the passport format depends on the issuing country
+	format := defaultPassportFormat
+	if guest.Country != "" {
+		code, ok := validator.CountryCode(guest.Country)
+		v.Check(ok, "country", "must be an ISO 3166 country name or code")
+
+		if countryFormat, ok := passportFormats[code]; ok {
+			format = countryFormat
+		}
+	}
+
 	v.Check(guest.PassportNumber != "", "passport_number", "must be provided")
+	v.Check(validator.Matches(guest.PassportNumber, format), "passport_number", "must be a valid passport number for the issuing country")
 }
 
-// GuestModel holds a handler to the database
+// GuestStore stores guests. GuestModel stores them in PostgreSQL and
+// MemoryGuestStore in memory for handler tests.
+type GuestStore interface {
+	Insert(ctx context.Context, guest *Guest) error
+	EmailInUse(ctx context.Context, email, passport string) (bool, error)
+	Get(ctx context.Context, passport string) (*Guest, error)
+	GetAll(ctx context.Context, gf GuestFilters, filters Filters) ([]*Guest, Metadata, error)
+	Update(ctx context.Context, guest *Guest) error
+	Delete(ctx context.Context, passport string) error
+}
+
+// GuestModel holds a handler to the database and the timeout of its queries
 type GuestModel struct {
-	DB *sql.DB
+	DB      *sql.DB
+	Timeout time.Duration
 }
 
-// Insert creates a record in tables person and guest.
-func (g GuestModel) Insert(guest *Guest) error {
-	query := `SELECT * FROM fn_create_guest($1, $2, $3, $4, $5, $6, $7, $8)`
+// Insert creates a record in tables person and guest. A passport number that
+// already belongs to a guest returns ErrDuplicatePassport.
+func (g GuestModel) Insert(ctx context.Context, guest *Guest) error {
+	// empty optional person attributes are stored as NULL
+	query := `SELECT * FROM fn_create_guest($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, ''), NULLIF($8, ''))`
 
 	args := []any{
 		guest.PassportNumber,
@@ -50,22 +121,62 @@ func (g GuestModel) Insert(guest *Guest) error {
 		guest.Country,
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, g.Timeout)
 	defer cancel()
 
-	return g.DB.QueryRowContext(ctx, query, args...).Scan(
+	err := g.DB.QueryRowContext(ctx, query, args...).Scan(
 		// scan remaining attributes
 		&guest.ID,
 		&guest.CreatedAt,
+		&guest.Version,
 	)
+
+	return translateError(err)
+}
+
+// EmailInUse reports whether a contact email belongs to a guest other than the
+// one with the given passport number.
+func (g GuestModel) EmailInUse(ctx context.Context, email, passport string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM guest
+			WHERE contact_email = $1 AND passport_number <> $2
+		)`
+
+	var inUse bool
+
+	ctx, cancel := context.WithTimeout(ctx, g.Timeout)
+	defer cancel()
+
+	err := g.DB.QueryRowContext(ctx, query, email, passport).Scan(&inUse)
+	if err != nil {
+		return false, translateError(err)
+	}
+
+	return inUse, nil
 }
 
 // Get reads a guest's passport and returns a Guest.
-func (g GuestModel) Get(passport string) (*Guest, error) {
-	query := `SELECT * FROM fn_get_guest($1)`
+func (g GuestModel) Get(ctx context.Context, passport string) (*Guest, error) {
+	query := `
+		SELECT
+			id,
+			passport_number,
+			contact_email,
+			contact_phone,
+			name,
+			COALESCE(gender, ''),
+			COALESCE(street, ''),
+			COALESCE(city, ''),
+			COALESCE(country, ''),
+			created_at,
+			version
+		FROM fn_get_guest($1)`
+
 	var guest Guest
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, g.Timeout)
 	defer cancel()
 
 	err := g.DB.QueryRowContext(ctx, query, passport).Scan(
@@ -80,6 +191,7 @@ func (g GuestModel) Get(passport string) (*Guest, error) {
 		&guest.City,
 		&guest.Country,
 		&guest.CreatedAt,
+		&guest.Version,
 	)
 
 	if err != nil {
@@ -87,49 +199,114 @@ func (g GuestModel) Get(passport string) (*Guest, error) {
 		case errors.Is(err, sql.ErrNoRows):
 			return nil, ErrRecordNotFound
 		default:
-			return nil, err
+			return nil, translateError(err)
 		}
 	}
 
 	return &guest, nil
 }
 
-// GetAll reads all guests in the database, filtered by name.
-func (g GuestModel) GetAll(name string) ([]*Guest, error) {
-	query := `
-		SELECT 
+// GuestFilters holds the values that guests can be filtered by. Empty strings
+// and zero times are ignored.
+type GuestFilters struct {
+	Name          string
+	Email         string
+	Country       string
+	City          string
+	CreatedAfter  time.Time // inclusive
+	CreatedBefore time.Time // exclusive
+}
+
+// guestSortColumns maps the sort values permitted for guests to their SQL
+// expressions and the type their cursor values are cast to.
+var guestSortColumns = map[string]struct{ expr, cast string }{
+	"name":            {"p.name", "text"},
+	"passport_number": {"g.passport_number", "text"},
+	"created_at":      {"p.created_at", "timestamptz"},
+	"country":         {"COALESCE(p.country, '')", "text"},
+}
+
+// GuestSortSafelist contains the sort values permitted for guests.
+var GuestSortSafelist = []string{
+	"name", "passport_number", "created_at", "country",
+	"-name", "-passport_number", "-created_at", "-country",
+}
+
+// GetAll reads a page of guests in the database, filtered and sorted. The
+// passport number is used to break ties in sorting and as the cursor key.
+func (g GuestModel) GetAll(ctx context.Context, gf GuestFilters, filters Filters) ([]*Guest, Metadata, error) {
+	sort := guestSortColumns[filters.sortColumn()]
+
+	args := []any{
+		gf.Name,
+		gf.Email,
+		gf.Country,
+		gf.City,
+		sql.NullTime{Time: gf.CreatedAfter, Valid: !gf.CreatedAfter.IsZero()},
+		sql.NullTime{Time: gf.CreatedBefore, Valid: !gf.CreatedBefore.IsZero()},
+		filters.limit(),
+		filters.offset(),
+	}
+
+	// only rows after the cursor are selected for keyset pagination
+	keyset := ""
+	if filters.Cursor != "" {
+		c, err := decodeCursor(filters.Cursor)
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+
+		keyset = fmt.Sprintf("AND (%s, g.passport_number) %s ($9::%s, $10)",
+			sort.expr, filters.keysetOperator(), sort.cast)
+		args = append(args, c.Value, c.Key)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT
+			count(*) OVER(),
 			g.id,
 			g.passport_number,
 			g.contact_email,
 			g.contact_phone,
 			p.name,
-			p.gender,
-			p.street,
-			p.city,
-			p.country,
-			p.created_at
+			COALESCE(p.gender, ''),
+			COALESCE(p.street, ''),
+			COALESCE(p.city, ''),
+			COALESCE(p.country, ''),
+			p.created_at,
+			g.version
 		FROM guest g
 		JOIN person p ON p.id = g.id
-		WHERE ($1 = '' OR p.name ILIKE '%' || $1 || '%')
-		ORDER BY g.passport_number ASC`
-
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		WHERE ($1 = '' OR p.name ILIKE '%%' || $1 || '%%')
+			AND ($2 = '' OR g.contact_email = $2::citext)
+			AND ($3 = '' OR LOWER(p.country) = LOWER($3))
+			AND ($4 = '' OR LOWER(p.city) = LOWER($4))
+			AND ($5::timestamptz IS NULL OR p.created_at >= $5)
+			AND ($6::timestamptz IS NULL OR p.created_at < $6)
+			%s
+		ORDER BY %s %s, g.passport_number %s
+		LIMIT $7 OFFSET $8`,
+		keyset, sort.expr, filters.sortDirection(), filters.sortDirection())
+
+	ctx, cancel := context.WithTimeout(ctx, g.Timeout)
 	defer cancel()
 
 	// retrieves rows from the database
-	rows, err := g.DB.QueryContext(ctx, query, name)
+	rows, err := g.DB.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return nil, Metadata{}, translateError(err)
 	}
 	defer rows.Close()
 
 	// construct the array of guests
+	totalRecords := 0
 	guests := []*Guest{}
 	for rows.Next() {
 		var guest Guest
 
 		err := rows.Scan(
 			// scan all attributes
+			&totalRecords,
 			&guest.ID,
 			&guest.PassportNumber,
 			&guest.ContactEmail,
@@ -140,27 +317,71 @@ func (g GuestModel) GetAll(name string) ([]*Guest, error) {
 			&guest.City,
 			&guest.Country,
 			&guest.CreatedAt,
+			&guest.Version,
 		)
 		if err != nil {
-			return nil, err
+			return nil, Metadata{}, translateError(err)
 		}
 
 		guests = append(guests, &guest)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, err
+		return nil, Metadata{}, translateError(err)
 	}
 
-	return guests, nil
+	return guests, guestMetadata(guests, totalRecords, filters), nil
+}
+
+// guestMetadata returns the Metadata of a page of guests out of totalRecords,
+// including the cursor of the next page if there is one.
+func guestMetadata(guests []*Guest, totalRecords int, filters Filters) Metadata {
+	// with a cursor the count only includes the remaining rows, so only the next
+	// cursor is meaningful
+	var metadata Metadata
+	if filters.Cursor == "" {
+		metadata = calculateMetadata(totalRecords, filters.Page, filters.PageSize)
+	} else {
+		metadata = Metadata{PageSize: filters.PageSize}
+	}
+
+	if totalRecords > len(guests)+filters.offset() && len(guests) > 0 {
+		last := guests[len(guests)-1]
+		metadata.NextCursor = encodeCursor(cursor{
+			Value: guestSortValue(last, filters.sortColumn()),
+			Key:   last.PassportNumber,
+			Sort:  filters.Sort,
+		})
+	}
+
+	return metadata
+}
+
+// guestSortValue returns the value of a guest's sort column as a string for
+// use in a cursor.
+func guestSortValue(guest *Guest, column string) string {
+	switch column {
+	case "name":
+		return guest.Name
+	case "created_at":
+		return guest.CreatedAt.Format(time.RFC3339Nano)
+	case "country":
+		return guest.Country
+	default:
+		return guest.PassportNumber
+	}
 }
 
-// Update modifies the appropriate person and guest records for a guest.
-func (g GuestModel) Update(guest *Guest) error {
-	query := `SELECT fn_update_guest($1, $2, $3, $4, $5, $6, $7, $8)`
+// Update modifies the appropriate person and guest records for a guest. The
+// update only happens if the guest is still at the version that was read, in
+// which case guest.Version is set to the new version.
+func (g GuestModel) Update(ctx context.Context, guest *Guest) error {
+	// empty optional person attributes are stored as NULL
+	query := `SELECT fn_update_guest($1, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, ''), NULLIF($8, ''), NULLIF($9, ''))`
 
 	args := []any{
 		guest.PassportNumber,
+		guest.Version,
 		guest.ContactEmail,
 		guest.ContactPhone,
 		guest.Name,
@@ -170,51 +391,24 @@ func (g GuestModel) Update(guest *Guest) error {
 		guest.Country,
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, g.Timeout)
 	defer cancel()
 
-	result, err := g.DB.ExecContext(ctx, query, args...)
-	if err != nil {
-		return err
-	}
-
-	// No rows being affected means the guest's passport is not in the database
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return err
-	}
-
-	if rowsAffected == 0 {
-		return ErrRecordNotFound
-	}
-
-	return nil
+	// fn_update_guest raises [guest-not-found] if the passport is not in the database
+	// and [edit-conflict] if the version no longer matches
+	err := g.DB.QueryRowContext(ctx, query, args...).Scan(&guest.Version)
+	return translateError(err)
 }
 
 // Delete removes a guest from the database and their associated reservations
 // and registrations.
-func (g GuestModel) Delete(passport string) error {
+func (g GuestModel) Delete(ctx context.Context, passport string) error {
 	query := `SELECT fn_delete_guest($1)`
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, g.Timeout)
 	defer cancel()
 
-	result, err := g.DB.ExecContext(ctx, query, passport)
-	if err != nil {
-		return err
-	}
-
-	// No rows being affected means the guest's passport is not in the database
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return err
-	}
-
-	if rowsAffected == 0 {
-		return ErrRecordNotFound
-	}
-
-	return nil
+	// fn_delete_guest raises [guest-not-found] if the passport is not in the database
+	_, err := g.DB.ExecContext(ctx, query, passport)
+	return translateError(err)
 }
@@ -0,0 +1,248 @@
+package data
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// MemoryGuestStore stores guests in memory. It returns the same errors as the
+// PL/pgSQL guest functions and constraints so handlers can be tested without
+// PostgreSQL.
+type MemoryGuestStore struct {
+	mu     sync.Mutex
+	nextID int64
+	guests map[string]*Guest // keyed by passport number
+}
+
+var _ GuestStore = (*MemoryGuestStore)(nil)
+
+// NewMemoryGuestStore returns an empty MemoryGuestStore.
+func NewMemoryGuestStore() *MemoryGuestStore {
+	return &MemoryGuestStore{
+		nextID: 1,
+		guests: make(map[string]*Guest),
+	}
+}
+
+// Insert stores a copy of a guest. A passport number that already belongs to a
+// guest returns ErrDuplicatePassport.
+func (s *MemoryGuestStore) Insert(ctx context.Context, guest *Guest) error {
+	if err := ctx.Err(); err != nil {
+		return translateError(err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.guests[guest.PassportNumber]; ok {
+		return translateError(&pq.Error{Code: "23505", Constraint: "guest_passport_number_key"})
+	}
+
+	// assign the attributes set by the database
+	guest.ID = s.nextID
+	guest.CreatedAt = time.Now().Truncate(time.Second)
+	guest.Version = 1
+	s.nextID++
+
+	stored := *guest
+	s.guests[guest.PassportNumber] = &stored
+
+	return nil
+}
+
+// EmailInUse reports whether a contact email belongs to a guest other than the
+// one with the given passport number. Emails are compared case-insensitively.
+func (s *MemoryGuestStore) EmailInUse(ctx context.Context, email, passport string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, translateError(err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, guest := range s.guests {
+		if strings.EqualFold(guest.ContactEmail, email) && guest.PassportNumber != passport {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// Get returns a copy of the guest with the passport number.
+func (s *MemoryGuestStore) Get(ctx context.Context, passport string) (*Guest, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, translateError(err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.guests[passport]
+	if !ok {
+		return nil, ErrRecordNotFound
+	}
+
+	guest := *stored
+	return &guest, nil
+}
+
+// GetAll returns a page of copies of the guests, filtered and sorted like
+// GuestModel.GetAll. Values are compared byte-wise rather than by collation.
+func (s *MemoryGuestStore) GetAll(ctx context.Context, gf GuestFilters, filters Filters) ([]*Guest, Metadata, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, Metadata{}, translateError(err)
+	}
+
+	column := filters.sortColumn()
+
+	var after *cursor
+	if filters.Cursor != "" {
+		c, err := decodeCursor(filters.Cursor)
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+		after = &c
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// select the guests that match the filters and come after the cursor
+	matched := []*Guest{}
+	for _, stored := range s.guests {
+		if !gf.matches(stored) {
+			continue
+		}
+
+		if after != nil {
+			order := compareGuestSortValues(column, guestSortValue(stored, column), after.Value)
+			if order == 0 {
+				order = strings.Compare(stored.PassportNumber, after.Key)
+			}
+			if filters.sortDirection() == "DESC" {
+				order = -order
+			}
+			if order <= 0 {
+				continue
+			}
+		}
+
+		guest := *stored
+		matched = append(matched, &guest)
+	}
+
+	// the passport number breaks ties in sorting
+	slices.SortFunc(matched, func(a, b *Guest) int {
+		order := compareGuestSortValues(column, guestSortValue(a, column), guestSortValue(b, column))
+		if order == 0 {
+			order = strings.Compare(a.PassportNumber, b.PassportNumber)
+		}
+		if filters.sortDirection() == "DESC" {
+			return -order
+		}
+		return order
+	})
+
+	totalRecords := len(matched)
+	start := min(filters.offset(), totalRecords)
+	end := min(start+filters.limit(), totalRecords)
+	guests := matched[start:end]
+
+	return guests, guestMetadata(guests, totalRecords, filters), nil
+}
+
+// matches reports whether a guest satisfies the filters, as in the WHERE
+// clause of GuestModel.GetAll.
+func (gf GuestFilters) matches(guest *Guest) bool {
+	switch {
+	case gf.Name != "" && !strings.Contains(strings.ToLower(guest.Name), strings.ToLower(gf.Name)):
+		return false
+	case gf.Email != "" && !strings.EqualFold(guest.ContactEmail, gf.Email):
+		return false
+	case gf.Country != "" && !strings.EqualFold(guest.Country, gf.Country):
+		return false
+	case gf.City != "" && !strings.EqualFold(guest.City, gf.City):
+		return false
+	case !gf.CreatedAfter.IsZero() && guest.CreatedAt.Before(gf.CreatedAfter):
+		return false
+	case !gf.CreatedBefore.IsZero() && !guest.CreatedAt.Before(gf.CreatedBefore):
+		return false
+	}
+
+	return true
+}
+
+// compareGuestSortValues compares two values of a guest sort column, with
+// creation dates compared as times.
+func compareGuestSortValues(column, a, b string) int {
+	if column == "created_at" {
+		ta, _ := time.Parse(time.RFC3339Nano, a)
+		tb, _ := time.Parse(time.RFC3339Nano, b)
+		return ta.Compare(tb)
+	}
+
+	return cmp.Compare(a, b)
+}
+
+// Update replaces a stored guest if it is still at the version that was read,
+// in which case guest.Version is set to the new version.
+func (s *MemoryGuestStore) Update(ctx context.Context, guest *Guest) error {
+	if err := ctx.Err(); err != nil {
+		return translateError(err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.guests[guest.PassportNumber]
+	if !ok {
+		return translateError(&pq.Error{
+			Code:    "P0001",
+			Message: fmt.Sprintf("[guest-not-found] Guest with passport %s does not exist", guest.PassportNumber),
+		})
+	}
+
+	if stored.Version != guest.Version {
+		return translateError(&pq.Error{
+			Code:    "P0001",
+			Message: fmt.Sprintf("[edit-conflict] Guest with passport %s is no longer at version %d", guest.PassportNumber, guest.Version),
+		})
+	}
+
+	guest.Version++
+
+	updated := *guest
+	updated.ID = stored.ID
+	updated.CreatedAt = stored.CreatedAt
+	s.guests[guest.PassportNumber] = &updated
+
+	return nil
+}
+
+// Delete removes a stored guest.
+func (s *MemoryGuestStore) Delete(ctx context.Context, passport string) error {
+	if err := ctx.Err(); err != nil {
+		return translateError(err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.guests[passport]; !ok {
+		return translateError(&pq.Error{
+			Code:    "P0001",
+			Message: fmt.Sprintf("[guest-not-found] Guest with passport %s does not exist", passport),
+		})
+	}
+
+	delete(s.guests, passport)
+	return nil
+}
@@ -0,0 +1,58 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/andreshungbz/lab4-database-crud/internal/validator"
+)
+
+func TestValidateGuest(t *testing.T) {
+	valid := Guest{
+		PassportNumber: "A1234567",
+		ContactEmail:   "mae@example.com",
+		ContactPhone:   "501-111-1234",
+		Name:           "Mae Smith",
+		Gender:         "F",
+		Street:         "12 Oak St",
+		City:           "Belize City",
+		Country:        "Belize",
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(g *Guest)
+		wantKey string
+	}{
+		{"valid", func(g *Guest) {}, ""},
+		{"country code", func(g *Guest) { g.Country = "BLZ" }, ""},
+		{"international phone", func(g *Guest) { g.ContactPhone = "+5011111234" }, ""},
+		{"no optional fields", func(g *Guest) { g.Gender, g.Street, g.City, g.Country = "", "", "", "" }, ""},
+		{"bad email", func(g *Guest) { g.ContactEmail = "mae@" }, "contact_email"},
+		{"short phone", func(g *Guest) { g.ContactPhone = "12345" }, "contact_phone"},
+		{"letters in phone", func(g *Guest) { g.ContactPhone = "501-CALL-NOW" }, "contact_phone"},
+		{"unknown gender", func(g *Guest) { g.Gender = "Q" }, "gender"},
+		{"unknown country", func(g *Guest) { g.Country = "Atlantis" }, "country"},
+		{"passport of another format", func(g *Guest) { g.PassportNumber = "123456789" }, "passport_number"},
+		{"passport of default format", func(g *Guest) { g.Country = "Norway"; g.PassportNumber = "123456789" }, ""},
+		{"missing name", func(g *Guest) { g.Name = "" }, "name"},
+	}
+
+	for _, tt := range tests {
+		guest := valid
+		tt.modify(&guest)
+
+		v := validator.New()
+		ValidateGuest(v, &guest)
+
+		if tt.wantKey == "" {
+			if !v.Valid() {
+				t.Errorf("%s: expected valid, got %v", tt.name, v.Errors)
+			}
+			continue
+		}
+
+		if _, ok := v.Errors[tt.wantKey]; !ok || len(v.Errors) != 1 {
+			t.Errorf("%s: expected a single error for %q, got %v", tt.name, tt.wantKey, v.Errors)
+		}
+	}
+}
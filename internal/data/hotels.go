@@ -0,0 +1,197 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/andreshungbz/lab4-database-crud/internal/validator"
+)
+
+// Hotel maps the hotel entity.
+type Hotel struct {
+	ID      int64  `json:"id"`
+	Name    string `json:"name"`
+	Street  string `json:"street"`
+	City    string `json:"city"`
+	State   string `json:"state"`
+	Country string `json:"country"`
+	Phone   string `json:"phone"`
+}
+
+// ValidateHotel checks that every hotel attribute is provided and not too long.
+func ValidateHotel(v *validator.Validator, hotel *Hotel) {
+	fields := map[string]string{
+		"name":    hotel.Name,
+		"street":  hotel.Street,
+		"city":    hotel.City,
+		"state":   hotel.State,
+		"country": hotel.Country,
+		"phone":   hotel.Phone,
+	}
+
+	for key, value := range fields {
+		v.Check(value != "", key, "must be provided")
+		v.Check(len(value) <= 200, key, "must not be more than 200 bytes long")
+	}
+}
+
+// HotelModel holds a handler to the database and the timeout of its queries
+type HotelModel struct {
+	DB      *sql.DB
+	Timeout time.Duration
+}
+
+// Insert creates a record in table hotel.
+func (m HotelModel) Insert(ctx context.Context, hotel *Hotel) error {
+	query := `
+		INSERT INTO hotel (name, street, city, state, country, phone)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`
+
+	args := []any{hotel.Name, hotel.Street, hotel.City, hotel.State, hotel.Country, hotel.Phone}
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&hotel.ID)
+	return translateError(err)
+}
+
+// Get reads a hotel's id and returns a Hotel.
+func (m HotelModel) Get(ctx context.Context, id int64) (*Hotel, error) {
+	query := `
+		SELECT id, name, street, city, state, country, phone
+		FROM hotel
+		WHERE id = $1`
+
+	var hotel Hotel
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, id).Scan(
+		&hotel.ID,
+		&hotel.Name,
+		&hotel.Street,
+		&hotel.City,
+		&hotel.State,
+		&hotel.Country,
+		&hotel.Phone,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrHotelNotFound
+		default:
+			return nil, translateError(err)
+		}
+	}
+
+	return &hotel, nil
+}
+
+// GetAll reads all hotels in the database.
+func (m HotelModel) GetAll(ctx context.Context) ([]*Hotel, error) {
+	query := `
+		SELECT id, name, street, city, state, country, phone
+		FROM hotel
+		ORDER BY id ASC`
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	// retrieves rows from the database
+	rows, err := m.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	// construct the array of hotels
+	hotels := []*Hotel{}
+	for rows.Next() {
+		var hotel Hotel
+
+		err := rows.Scan(
+			&hotel.ID,
+			&hotel.Name,
+			&hotel.Street,
+			&hotel.City,
+			&hotel.State,
+			&hotel.Country,
+			&hotel.Phone,
+		)
+		if err != nil {
+			return nil, translateError(err)
+		}
+
+		hotels = append(hotels, &hotel)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, translateError(err)
+	}
+
+	return hotels, nil
+}
+
+// Update modifies a hotel record.
+func (m HotelModel) Update(ctx context.Context, hotel *Hotel) error {
+	query := `
+		UPDATE hotel
+		SET name = $1, street = $2, city = $3, state = $4, country = $5, phone = $6
+		WHERE id = $7`
+
+	args := []any{hotel.Name, hotel.Street, hotel.City, hotel.State, hotel.Country, hotel.Phone, hotel.ID}
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, args...)
+	if err != nil {
+		return translateError(err)
+	}
+
+	// No rows being affected means the hotel is not in the database
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return translateError(err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrHotelNotFound
+	}
+
+	return nil
+}
+
+// Delete removes a hotel from the database along with its rooms, employees,
+// and their associated records.
+func (m HotelModel) Delete(ctx context.Context, id int64) error {
+	query := `DELETE FROM hotel WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id)
+	if err != nil {
+		return translateError(err)
+	}
+
+	// No rows being affected means the hotel is not in the database
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return translateError(err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrHotelNotFound
+	}
+
+	return nil
+}
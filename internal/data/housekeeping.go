@@ -0,0 +1,355 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/andreshungbz/lab4-database-crud/internal/validator"
+)
+
+// Shifts mirroring the shift_type enum in the database.
+const (
+	ShiftDay   = "day"
+	ShiftNight = "night"
+)
+
+// TaskStatuses are the values housekeeping tasks can be filtered by.
+var TaskStatuses = []string{"open", "completed", "all"}
+
+// ShiftSchedule decides which shift is working. The day shift runs from the
+// DayStart hour until the DayEnd hour in the hotel's Location, and the night
+// shift the rest of the day.
+type ShiftSchedule struct {
+	DayStart int
+	DayEnd   int
+	Location *time.Location
+}
+
+// ValidateShiftSchedule checks the hours of a shift schedule.
+func ValidateShiftSchedule(v *validator.Validator, s ShiftSchedule) {
+	v.Check(s.DayStart >= 0 && s.DayStart <= 23, "day_start", "must be an hour from 0 to 23")
+	v.Check(s.DayEnd >= 1 && s.DayEnd <= 24, "day_end", "must be an hour from 1 to 24")
+	v.Check(s.DayStart < s.DayEnd, "day_end", "must be after the day shift start")
+}
+
+// Current returns the shift working at a time.
+func (s ShiftSchedule) Current(t time.Time) string {
+	hour := t.In(s.Location).Hour()
+	if hour >= s.DayStart && hour < s.DayEnd {
+		return ShiftDay
+	}
+
+	return ShiftNight
+}
+
+// HousekeepingTask maps the housekeeping_task entity. Tasks without a
+// housekeeper are unassigned.
+type HousekeepingTask struct {
+	ID            int64      `json:"id"`
+	HotelID       int64      `json:"hotel_id"`
+	RoomNumber    int        `json:"room_number"`
+	HousekeeperID *int64     `json:"housekeeper_id"`
+	TaskType      string     `json:"task_type"`
+	CreatedAt     time.Time  `json:"created_at"`
+	CompletedAt   *time.Time `json:"completed_at"`
+}
+
+// TaskFilters holds the values that housekeeping tasks can be filtered by.
+// Zero values are ignored.
+type TaskFilters struct {
+	HotelID       int64
+	HousekeeperID int64
+	RoomNumber    int
+	Status        string
+}
+
+// ValidateTaskFilters checks the housekeeping task filter values.
+func ValidateTaskFilters(v *validator.Validator, tf TaskFilters) {
+	v.Check(tf.HotelID >= 0, "hotel_id", "must not be negative")
+	v.Check(tf.HousekeeperID >= 0, "housekeeper_id", "must not be negative")
+	v.Check(tf.RoomNumber >= 0, "room_number", "must not be negative")
+	v.Check(validator.PermittedValue(tf.Status, TaskStatuses...), "status", "must be one of open, completed, or all")
+}
+
+// HousekeepingModel holds a handler to the database and the timeout of its queries
+type HousekeepingModel struct {
+	DB      *sql.DB
+	Timeout time.Duration
+}
+
+// Get reads a task's id and returns a HousekeepingTask.
+func (m HousekeepingModel) Get(ctx context.Context, id int64) (*HousekeepingTask, error) {
+	query := `
+		SELECT id, hotel_id, room_number, housekeeper_id, task_type, created_at, completed_at
+		FROM housekeeping_task
+		WHERE id = $1`
+
+	var task HousekeepingTask
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, id).Scan(
+		&task.ID,
+		&task.HotelID,
+		&task.RoomNumber,
+		&task.HousekeeperID,
+		&task.TaskType,
+		&task.CreatedAt,
+		&task.CompletedAt,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, translateError(err)
+		}
+	}
+
+	return &task, nil
+}
+
+// GetAll reads all housekeeping tasks, filtered by hotel, housekeeper, room,
+// and whether they are complete. Oldest tasks are listed first.
+func (m HousekeepingModel) GetAll(ctx context.Context, tf TaskFilters) ([]*HousekeepingTask, error) {
+	query := `
+		SELECT id, hotel_id, room_number, housekeeper_id, task_type, created_at, completed_at
+		FROM housekeeping_task
+		WHERE ($1 = 0 OR hotel_id = $1)
+			AND ($2 = 0 OR housekeeper_id = $2)
+			AND ($3 = 0 OR room_number = $3)
+			AND ($4 = 'all'
+				OR ($4 = 'open' AND completed_at IS NULL)
+				OR ($4 = 'completed' AND completed_at IS NOT NULL))
+		ORDER BY created_at ASC, id ASC`
+
+	args := []any{tf.HotelID, tf.HousekeeperID, tf.RoomNumber, tf.Status}
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	// retrieves rows from the database
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	// construct the array of tasks
+	tasks := []*HousekeepingTask{}
+	for rows.Next() {
+		var task HousekeepingTask
+
+		err := rows.Scan(
+			&task.ID,
+			&task.HotelID,
+			&task.RoomNumber,
+			&task.HousekeeperID,
+			&task.TaskType,
+			&task.CreatedAt,
+			&task.CompletedAt,
+		)
+		if err != nil {
+			return nil, translateError(err)
+		}
+
+		tasks = append(tasks, &task)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, translateError(err)
+	}
+
+	return tasks, nil
+}
+
+// Assign gives an open task to an employed housekeeper of the task's hotel who
+// works the given shift. Assigned tasks can be reassigned the same way.
+func (m HousekeepingModel) Assign(ctx context.Context, id, housekeeperID int64, shift string) (*HousekeepingTask, error) {
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer tx.Rollback()
+
+	task, err := lockTask(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// ensure the housekeeper can take the task
+	query := `
+		SELECT h.shift, e.hotel_id
+		FROM housekeeper h
+		JOIN employee e ON e.id = h.id
+		WHERE h.id = $1 AND e.employed = TRUE`
+
+	var housekeeperShift string
+	var housekeeperHotelID int64
+
+	err = tx.QueryRowContext(ctx, query, housekeeperID).Scan(&housekeeperShift, &housekeeperHotelID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrHousekeeperNotFound
+		default:
+			return nil, translateError(err)
+		}
+	}
+
+	if housekeeperHotelID != task.HotelID {
+		return nil, ErrHousekeeperNotFound
+	}
+
+	if housekeeperShift != shift {
+		return nil, ErrWrongShift
+	}
+
+	query = `
+		UPDATE housekeeping_task
+		SET housekeeper_id = $1
+		WHERE id = $2`
+
+	_, err = tx.ExecContext(ctx, query, housekeeperID, id)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	task.HousekeeperID = &housekeeperID
+	return task, nil
+}
+
+// Complete marks an open task as done. If it was the room's last outstanding
+// task and the room is vacant/dirty and not out of order, the room becomes
+// vacant/clean on behalf of the employee and the status change is returned.
+func (m HousekeepingModel) Complete(ctx context.Context, id, employeeID int64) (*HousekeepingTask, *RoomStatusChange, error) {
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, translateError(err)
+	}
+	defer tx.Rollback()
+
+	task, err := lockTask(ctx, tx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// lock the room so that completions of its other tasks wait for this one,
+	// otherwise concurrent completions of the last tasks would each count the
+	// other as outstanding and the room would never be promoted
+	query := `
+		SELECT status_code
+		FROM room
+		WHERE hotel_id = $1 AND number = $2
+		FOR UPDATE`
+
+	_, err = tx.ExecContext(ctx, query, task.HotelID, task.RoomNumber)
+	if err != nil {
+		return nil, nil, translateError(err)
+	}
+
+	query = `
+		UPDATE housekeeping_task
+		SET completed_at = NOW()
+		WHERE id = $1
+		RETURNING completed_at`
+
+	err = tx.QueryRowContext(ctx, query, id).Scan(&task.CompletedAt)
+	if err != nil {
+		return nil, nil, translateError(err)
+	}
+
+	// check whether the room can be promoted
+	query = `
+		SELECT
+			r.status_code,
+			(SELECT COUNT(*)
+				FROM housekeeping_task t
+				WHERE t.hotel_id = r.hotel_id
+					AND t.room_number = r.number
+					AND t.completed_at IS NULL)
+		FROM room r
+		WHERE r.hotel_id = $1 AND r.number = $2`
+
+	var status string
+	var outstanding int
+
+	err = tx.QueryRowContext(ctx, query, task.HotelID, task.RoomNumber).Scan(&status, &outstanding)
+	if err != nil {
+		return nil, nil, translateError(err)
+	}
+
+	var change *RoomStatusChange
+	if status == RoomVacantDirty && outstanding == 0 {
+		// an out-of-order room stays dirty until its report is resolved
+		outOfOrder, err := roomOutOfOrder(ctx, tx, task.HotelID, task.RoomNumber)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if !outOfOrder {
+			change, err = updateRoomStatus(ctx, tx, task.HotelID, task.RoomNumber, RoomVacantClean, employeeID)
+			if err != nil {
+				return nil, nil, translateError(err)
+			}
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, nil, translateError(err)
+	}
+
+	return task, change, nil
+}
+
+// lockTask reads an open task for the rest of a transaction.
+func lockTask(ctx context.Context, tx *sql.Tx, id int64) (*HousekeepingTask, error) {
+	query := `
+		SELECT id, hotel_id, room_number, housekeeper_id, task_type, created_at, completed_at
+		FROM housekeeping_task
+		WHERE id = $1
+		FOR UPDATE`
+
+	var task HousekeepingTask
+
+	err := tx.QueryRowContext(ctx, query, id).Scan(
+		&task.ID,
+		&task.HotelID,
+		&task.RoomNumber,
+		&task.HousekeeperID,
+		&task.TaskType,
+		&task.CreatedAt,
+		&task.CompletedAt,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, translateError(err)
+		}
+	}
+
+	if task.CompletedAt != nil {
+		return nil, ErrTaskCompleted
+	}
+
+	return &task, nil
+}
@@ -0,0 +1,30 @@
+package data
+
+import (
+	"testing"
+	"time"
+)
+
+func TestShiftScheduleCurrent(t *testing.T) {
+	belize := time.FixedZone("CST", -6*60*60)
+	schedule := ShiftSchedule{DayStart: 7, DayEnd: 19, Location: belize}
+
+	tests := []struct {
+		at   time.Time
+		want string
+	}{
+		{time.Date(2025, 1, 1, 7, 0, 0, 0, belize), ShiftDay},
+		{time.Date(2025, 1, 1, 18, 59, 0, 0, belize), ShiftDay},
+		{time.Date(2025, 1, 1, 19, 0, 0, 0, belize), ShiftNight},
+		{time.Date(2025, 1, 1, 6, 59, 0, 0, belize), ShiftNight},
+		// the hour is read in the hotel's time zone, not the time's own
+		{time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC), ShiftNight},
+		{time.Date(2025, 1, 1, 14, 0, 0, 0, time.UTC), ShiftDay},
+	}
+
+	for _, tt := range tests {
+		if got := schedule.Current(tt.at); got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.at, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,407 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/andreshungbz/lab4-database-crud/internal/validator"
+)
+
+// MaintenanceDepartment is the department that technicians are assigned from.
+const MaintenanceDepartment = "Housekeeping & Maintenance"
+
+// Maintenance priorities and categories mirroring the maintenance_priority and
+// maintenance_category enums in the database. Priorities are in ascending order.
+var (
+	MaintenancePriorities = []string{"low", "normal", "high", "urgent"}
+	MaintenanceCategories = []string{"plumbing", "electrical", "hvac", "furniture", "appliance", "structural", "other"}
+)
+
+// ReportStatuses are the values maintenance reports can be filtered by.
+var ReportStatuses = []string{"open", "resolved", "all"}
+
+// MaintenanceReport maps the maintenance_report entity. Reports without a
+// completed_at time are open, and open reports that are out of order keep
+// their room from being assigned to reservations.
+type MaintenanceReport struct {
+	ID            int64      `json:"id"`
+	HotelID       int64      `json:"hotel_id"`
+	RoomNumber    int        `json:"room_number"`
+	HousekeeperID int64      `json:"housekeeper_id"`
+	TechnicianID  *int64     `json:"technician_id"`
+	Description   string     `json:"description"`
+	Priority      string     `json:"priority"`
+	Category      string     `json:"category"`
+	OutOfOrder    bool       `json:"out_of_order"`
+	CreatedAt     time.Time  `json:"created_at"`
+	CompletedAt   *time.Time `json:"completed_at"`
+}
+
+// ValidateMaintenanceReport checks the room, description, and triage details of
+// a maintenance report.
+func ValidateMaintenanceReport(v *validator.Validator, report *MaintenanceReport) {
+	v.Check(report.HotelID > 0, "hotel_id", "must be a positive integer")
+	v.Check(report.RoomNumber > 0, "room_number", "must be a positive integer")
+
+	v.Check(report.Description != "", "description", "must be provided")
+	v.Check(len(report.Description) <= 1000, "description", "must not be more than 1000 bytes long")
+
+	v.Check(validator.PermittedValue(report.Priority, MaintenancePriorities...), "priority", "must be one of low, normal, high, or urgent")
+	v.Check(validator.PermittedValue(report.Category, MaintenanceCategories...), "category", "must be one of plumbing, electrical, hvac, furniture, appliance, structural, or other")
+
+	if report.TechnicianID != nil {
+		v.Check(*report.TechnicianID > 0, "technician_id", "must be a positive integer")
+	}
+}
+
+// MaintenanceFilters holds the values that maintenance reports can be filtered
+// by. Zero values and empty strings are ignored.
+type MaintenanceFilters struct {
+	HotelID    int64
+	RoomNumber int
+	Priority   string
+	Category   string
+	Status     string
+}
+
+// ValidateMaintenanceFilters checks the maintenance report filter values.
+func ValidateMaintenanceFilters(v *validator.Validator, mf MaintenanceFilters) {
+	v.Check(mf.HotelID >= 0, "hotel_id", "must not be negative")
+	v.Check(mf.RoomNumber >= 0, "room_number", "must not be negative")
+	v.Check(validator.PermittedValue(mf.Status, ReportStatuses...), "status", "must be one of open, resolved, or all")
+
+	if mf.Priority != "" {
+		v.Check(validator.PermittedValue(mf.Priority, MaintenancePriorities...), "priority", "must be one of low, normal, high, or urgent")
+	}
+	if mf.Category != "" {
+		v.Check(validator.PermittedValue(mf.Category, MaintenanceCategories...), "category", "must be one of plumbing, electrical, hvac, furniture, appliance, structural, or other")
+	}
+}
+
+// MaintenanceModel holds a handler to the database and the timeout of its queries
+type MaintenanceModel struct {
+	DB      *sql.DB
+	Timeout time.Duration
+}
+
+// Insert creates a record in table maintenance_report filed by a housekeeper.
+func (m MaintenanceModel) Insert(ctx context.Context, report *MaintenanceReport) error {
+	// selecting from room distinguishes a missing room from a missing housekeeper
+	query := `
+		INSERT INTO maintenance_report (hotel_id, room_number, housekeeper_id, description, priority, category, out_of_order)
+		SELECT r.hotel_id, r.number, $3, $4, $5, $6, $7
+		FROM room r
+		WHERE r.hotel_id = $1 AND r.number = $2
+		RETURNING id, created_at`
+
+	args := []any{
+		report.HotelID,
+		report.RoomNumber,
+		report.HousekeeperID,
+		report.Description,
+		report.Priority,
+		report.Category,
+		report.OutOfOrder,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&report.ID, &report.CreatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrRoomNotFound
+		default:
+			return translateError(err)
+		}
+	}
+
+	return nil
+}
+
+// Get reads a report's id and returns a MaintenanceReport.
+func (m MaintenanceModel) Get(ctx context.Context, id int64) (*MaintenanceReport, error) {
+	query := `
+		SELECT id, hotel_id, room_number, housekeeper_id, technician_id, description,
+			priority, category, out_of_order, created_at, completed_at
+		FROM maintenance_report
+		WHERE id = $1`
+
+	var report MaintenanceReport
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, id).Scan(
+		&report.ID,
+		&report.HotelID,
+		&report.RoomNumber,
+		&report.HousekeeperID,
+		&report.TechnicianID,
+		&report.Description,
+		&report.Priority,
+		&report.Category,
+		&report.OutOfOrder,
+		&report.CreatedAt,
+		&report.CompletedAt,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, translateError(err)
+		}
+	}
+
+	return &report, nil
+}
+
+// GetAll reads all maintenance reports, filtered by hotel, room, priority,
+// category, and whether they are resolved. The most urgent reports are listed
+// first, oldest first within a priority.
+func (m MaintenanceModel) GetAll(ctx context.Context, mf MaintenanceFilters) ([]*MaintenanceReport, error) {
+	query := `
+		SELECT id, hotel_id, room_number, housekeeper_id, technician_id, description,
+			priority, category, out_of_order, created_at, completed_at
+		FROM maintenance_report
+		WHERE ($1 = 0 OR hotel_id = $1)
+			AND ($2 = 0 OR room_number = $2)
+			AND ($3 = '' OR priority::text = $3)
+			AND ($4 = '' OR category::text = $4)
+			AND ($5 = 'all'
+				OR ($5 = 'open' AND completed_at IS NULL)
+				OR ($5 = 'resolved' AND completed_at IS NOT NULL))
+		ORDER BY priority DESC, created_at ASC, id ASC`
+
+	args := []any{mf.HotelID, mf.RoomNumber, mf.Priority, mf.Category, mf.Status}
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	// retrieves rows from the database
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	// construct the array of reports
+	reports := []*MaintenanceReport{}
+	for rows.Next() {
+		var report MaintenanceReport
+
+		err := rows.Scan(
+			&report.ID,
+			&report.HotelID,
+			&report.RoomNumber,
+			&report.HousekeeperID,
+			&report.TechnicianID,
+			&report.Description,
+			&report.Priority,
+			&report.Category,
+			&report.OutOfOrder,
+			&report.CreatedAt,
+			&report.CompletedAt,
+		)
+		if err != nil {
+			return nil, translateError(err)
+		}
+
+		reports = append(reports, &report)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, translateError(err)
+	}
+
+	return reports, nil
+}
+
+// Update triages an open report, changing its description, priority, category,
+// technician, and whether its room is out of order. A technician must be an
+// employed member of the maintenance department at the report's hotel.
+func (m MaintenanceModel) Update(ctx context.Context, report *MaintenanceReport) error {
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return translateError(err)
+	}
+	defer tx.Rollback()
+
+	current, err := lockReport(ctx, tx, report.ID)
+	if err != nil {
+		return err
+	}
+
+	if current.CompletedAt != nil {
+		return ErrReportResolved
+	}
+
+	// ensure the technician can take the report
+	if report.TechnicianID != nil {
+		query := `
+			SELECT EXISTS (
+				SELECT 1
+				FROM employee
+				WHERE id = $1
+					AND hotel_id = $2
+					AND department = $3
+					AND employed = TRUE
+			)`
+
+		var exists bool
+		err = tx.QueryRowContext(ctx, query, *report.TechnicianID, current.HotelID, MaintenanceDepartment).Scan(&exists)
+		if err != nil {
+			return translateError(err)
+		}
+
+		if !exists {
+			return ErrTechnicianNotFound
+		}
+	}
+
+	query := `
+		UPDATE maintenance_report
+		SET description = $1, priority = $2, category = $3, technician_id = $4, out_of_order = $5
+		WHERE id = $6`
+
+	args := []any{
+		report.Description,
+		report.Priority,
+		report.Category,
+		report.TechnicianID,
+		report.OutOfOrder,
+		report.ID,
+	}
+
+	_, err = tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return translateError(err)
+	}
+
+	return tx.Commit()
+}
+
+// Resolve marks an open report as done, returning its room to service if it
+// was out of order.
+func (m MaintenanceModel) Resolve(ctx context.Context, id int64) (*MaintenanceReport, error) {
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer tx.Rollback()
+
+	report, err := lockReport(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if report.CompletedAt != nil {
+		return nil, ErrReportResolved
+	}
+
+	query := `
+		UPDATE maintenance_report
+		SET completed_at = NOW()
+		WHERE id = $1
+		RETURNING completed_at`
+
+	err = tx.QueryRowContext(ctx, query, id).Scan(&report.CompletedAt)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return report, nil
+}
+
+// Reopen marks a resolved report as open again. An out-of-order report takes
+// its room out of service again.
+func (m MaintenanceModel) Reopen(ctx context.Context, id int64) (*MaintenanceReport, error) {
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer tx.Rollback()
+
+	report, err := lockReport(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if report.CompletedAt == nil {
+		return nil, ErrReportNotResolved
+	}
+
+	query := `
+		UPDATE maintenance_report
+		SET completed_at = NULL
+		WHERE id = $1`
+
+	_, err = tx.ExecContext(ctx, query, id)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	report.CompletedAt = nil
+	return report, nil
+}
+
+// lockReport reads a report for the rest of a transaction.
+func lockReport(ctx context.Context, tx *sql.Tx, id int64) (*MaintenanceReport, error) {
+	query := `
+		SELECT id, hotel_id, room_number, housekeeper_id, technician_id, description,
+			priority, category, out_of_order, created_at, completed_at
+		FROM maintenance_report
+		WHERE id = $1
+		FOR UPDATE`
+
+	var report MaintenanceReport
+
+	err := tx.QueryRowContext(ctx, query, id).Scan(
+		&report.ID,
+		&report.HotelID,
+		&report.RoomNumber,
+		&report.HousekeeperID,
+		&report.TechnicianID,
+		&report.Description,
+		&report.Priority,
+		&report.Category,
+		&report.OutOfOrder,
+		&report.CreatedAt,
+		&report.CompletedAt,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, translateError(err)
+		}
+	}
+
+	return &report, nil
+}
@@ -3,6 +3,7 @@ package data
 import (
 	"database/sql"
 	"errors"
+	"time"
 )
 
 var (
@@ -12,12 +13,37 @@ var (
 
 // Models groups all database models used in the application.
 type Models struct {
-	Guest GuestModel
+	Availability AvailabilityModel
+	Department   DepartmentModel
+	Employee     EmployeeStore
+	Guest        GuestStore
+	Hotel        HotelModel
+	Housekeeping HousekeepingModel
+	Maintenance  MaintenanceModel
+	Permission   PermissionStore
+	Reservation  ReservationModel
+	Room         RoomModel
+	RoomStatus   RoomStatusModel
+	RoomType     RoomTypeModel
+	Token        TokenModel
 }
 
-// NewModels returns all Models configured with the database handler.
-func NewModels(db *sql.DB) Models {
+// NewModels returns all Models configured with the database handler and the
+// timeout of each query.
+func NewModels(db *sql.DB, timeout time.Duration) Models {
 	return Models{
-		Guest: GuestModel{DB: db},
+		Availability: AvailabilityModel{DB: db, Timeout: timeout},
+		Department:   DepartmentModel{DB: db, Timeout: timeout},
+		Employee:     EmployeeModel{DB: db, Timeout: timeout},
+		Guest:        GuestModel{DB: db, Timeout: timeout},
+		Hotel:        HotelModel{DB: db, Timeout: timeout},
+		Housekeeping: HousekeepingModel{DB: db, Timeout: timeout},
+		Maintenance:  MaintenanceModel{DB: db, Timeout: timeout},
+		Permission:   PermissionModel{DB: db, Timeout: timeout},
+		Reservation:  ReservationModel{DB: db, Timeout: timeout},
+		Room:         RoomModel{DB: db, Timeout: timeout},
+		RoomStatus:   RoomStatusModel{DB: db, Timeout: timeout},
+		RoomType:     RoomTypeModel{DB: db, Timeout: timeout},
+		Token:        TokenModel{DB: db, Timeout: timeout},
 	}
 }
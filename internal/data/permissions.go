@@ -0,0 +1,149 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"time"
+)
+
+// Permission codes checked by the requirePermission middleware.
+const (
+	PermissionGuestsRead        = "guests:read"
+	PermissionGuestsWrite       = "guests:write"
+	PermissionReservationsRead  = "reservations:read"
+	PermissionReservationsWrite = "reservations:write"
+	PermissionRoomsRead         = "rooms:read"
+	PermissionRoomsStatus       = "rooms:status"
+	PermissionInventoryWrite    = "inventory:write"
+	PermissionTasksRead         = "tasks:read"
+	PermissionTasksWrite        = "tasks:write"
+	PermissionMaintenanceWrite  = "maintenance:write"
+	PermissionEmployeesRead     = "employees:read"
+	PermissionEmployeesWrite    = "employees:write"
+	PermissionFinancesRead      = "finances:read"
+)
+
+// Roles correspond to the employee subtype tables.
+const (
+	RoleOperationsManager = "operations_manager"
+	RoleHotelOwner        = "hotel_owner"
+	RoleFrontDesk         = "front_desk"
+	RoleHousekeeper       = "housekeeper"
+)
+
+// rolePermissions maps each role to the permissions it grants. Operations
+// managers read finances so that they can see the department budgets they may
+// override. Hotel owners are operations managers, so they are granted both sets.
+var rolePermissions = map[string]Permissions{
+	RoleOperationsManager: {
+		PermissionGuestsRead, PermissionGuestsWrite,
+		PermissionReservationsRead, PermissionReservationsWrite,
+		PermissionRoomsRead, PermissionRoomsStatus, PermissionInventoryWrite,
+		PermissionTasksRead, PermissionTasksWrite, PermissionMaintenanceWrite,
+		PermissionEmployeesRead, PermissionEmployeesWrite,
+		PermissionFinancesRead,
+	},
+	RoleHotelOwner: {
+		PermissionFinancesRead,
+	},
+	RoleFrontDesk: {
+		PermissionGuestsRead, PermissionGuestsWrite,
+		PermissionReservationsRead, PermissionReservationsWrite,
+		PermissionRoomsRead,
+	},
+	RoleHousekeeper: {
+		PermissionRoomsRead, PermissionRoomsStatus,
+		PermissionTasksRead, PermissionTasksWrite, PermissionMaintenanceWrite,
+	},
+}
+
+// Permissions holds the permission codes granted to an employee.
+type Permissions []string
+
+// Include reports whether a permission code is in Permissions.
+func (p Permissions) Include(code string) bool {
+	return slices.Contains(p, code)
+}
+
+// permissionsForRoles returns the combined permissions of the roles without
+// duplicates.
+func permissionsForRoles(roles []string) Permissions {
+	permissions := Permissions{}
+
+	for _, role := range roles {
+		for _, code := range rolePermissions[role] {
+			if !permissions.Include(code) {
+				permissions = append(permissions, code)
+			}
+		}
+	}
+
+	return permissions
+}
+
+// PermissionStore looks up the roles and permissions of employees.
+// PermissionModel derives them from PostgreSQL and MemoryPermissionStore from
+// roles set in memory for handler tests.
+type PermissionStore interface {
+	GetRolesForEmployee(ctx context.Context, employeeID int64) ([]string, error)
+	GetAllForEmployee(ctx context.Context, employeeID int64) (Permissions, error)
+}
+
+// PermissionModel holds a handler to the database and the timeout of its queries
+type PermissionModel struct {
+	DB      *sql.DB
+	Timeout time.Duration
+}
+
+// GetRolesForEmployee returns the roles of an employee derived from the
+// employee subtype tables they appear in.
+func (m PermissionModel) GetRolesForEmployee(ctx context.Context, employeeID int64) ([]string, error) {
+	query := `
+		SELECT
+			EXISTS (SELECT 1 FROM operations_manager WHERE id = $1),
+			EXISTS (SELECT 1 FROM operations_manager WHERE id = $1 AND hotel_owner),
+			EXISTS (SELECT 1 FROM front_desk WHERE id = $1),
+			EXISTS (SELECT 1 FROM housekeeper WHERE id = $1)`
+
+	var isManager, isOwner, isFrontDesk, isHousekeeper bool
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, employeeID).Scan(
+		&isManager,
+		&isOwner,
+		&isFrontDesk,
+		&isHousekeeper,
+	)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	roles := []string{}
+	if isManager {
+		roles = append(roles, RoleOperationsManager)
+	}
+	if isOwner {
+		roles = append(roles, RoleHotelOwner)
+	}
+	if isFrontDesk {
+		roles = append(roles, RoleFrontDesk)
+	}
+	if isHousekeeper {
+		roles = append(roles, RoleHousekeeper)
+	}
+
+	return roles, nil
+}
+
+// GetAllForEmployee returns the permissions granted by an employee's roles.
+func (m PermissionModel) GetAllForEmployee(ctx context.Context, employeeID int64) (Permissions, error) {
+	roles, err := m.GetRolesForEmployee(ctx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	return permissionsForRoles(roles), nil
+}
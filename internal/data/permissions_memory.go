@@ -0,0 +1,60 @@
+package data
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// MemoryPermissionStore holds the roles of employees in memory, where the
+// employee subtype tables would otherwise determine them.
+type MemoryPermissionStore struct {
+	mu    sync.Mutex
+	roles map[int64][]string // keyed by employee id
+}
+
+var _ PermissionStore = (*MemoryPermissionStore)(nil)
+
+// NewMemoryPermissionStore returns a MemoryPermissionStore in which no
+// employee has a role.
+func NewMemoryPermissionStore() *MemoryPermissionStore {
+	return &MemoryPermissionStore{
+		roles: make(map[int64][]string),
+	}
+}
+
+// SetRoles replaces the roles of an employee.
+func (s *MemoryPermissionStore) SetRoles(employeeID int64, roles ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.roles[employeeID] = slices.Clone(roles)
+}
+
+// GetRolesForEmployee returns the roles set for an employee. Employees
+// without roles have none, as with PermissionModel.
+func (s *MemoryPermissionStore) GetRolesForEmployee(ctx context.Context, employeeID int64) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, translateError(err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	roles := slices.Clone(s.roles[employeeID])
+	if roles == nil {
+		roles = []string{}
+	}
+
+	return roles, nil
+}
+
+// GetAllForEmployee returns the permissions granted by an employee's roles.
+func (s *MemoryPermissionStore) GetAllForEmployee(ctx context.Context, employeeID int64) (Permissions, error) {
+	roles, err := s.GetRolesForEmployee(ctx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	return permissionsForRoles(roles), nil
+}
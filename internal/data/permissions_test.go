@@ -0,0 +1,35 @@
+package data
+
+import "testing"
+
+func TestPermissionsForRoles(t *testing.T) {
+	// housekeepers may update room status and tasks but not guests
+	housekeeper := permissionsForRoles([]string{RoleHousekeeper})
+	if !housekeeper.Include(PermissionRoomsStatus) || !housekeeper.Include(PermissionTasksWrite) {
+		t.Errorf("expected housekeeper to update room status and tasks, got %v", housekeeper)
+	}
+	if housekeeper.Include(PermissionGuestsWrite) {
+		t.Errorf("expected housekeeper not to write guests, got %v", housekeeper)
+	}
+
+	// operations managers see the finances whose budgets they may override
+	manager := permissionsForRoles([]string{RoleOperationsManager})
+	if !manager.Include(PermissionFinancesRead) {
+		t.Errorf("expected operations manager to read finances, got %v", manager)
+	}
+
+	frontDesk := permissionsForRoles([]string{RoleFrontDesk})
+	if frontDesk.Include(PermissionFinancesRead) {
+		t.Errorf("expected front desk not to read finances, got %v", frontDesk)
+	}
+
+	owner := permissionsForRoles([]string{RoleOperationsManager, RoleHotelOwner})
+	if !owner.Include(PermissionFinancesRead) || !owner.Include(PermissionGuestsWrite) {
+		t.Errorf("expected hotel owner to read finances and write guests, got %v", owner)
+	}
+
+	// no roles grants no permissions
+	if none := permissionsForRoles(nil); len(none) != 0 {
+		t.Errorf("expected no permissions, got %v", none)
+	}
+}
@@ -0,0 +1,32 @@
+package data
+
+import (
+	"time"
+
+	"github.com/andreshungbz/lab4-database-crud/internal/validator"
+)
+
+// RefundPolicy decides how much of a canceled reservation's payment is
+// refunded. Canceling at least FreeDays days before the check-in date refunds
+// the full payment, and canceling later refunds LatePercentage percent of it.
+type RefundPolicy struct {
+	FreeDays       int
+	LatePercentage float64
+}
+
+// ValidateRefundPolicy checks the values of a refund policy.
+func ValidateRefundPolicy(v *validator.Validator, policy RefundPolicy) {
+	v.Check(policy.FreeDays >= 0, "free_days", "must not be negative")
+	v.Check(policy.LatePercentage >= 0, "late_percentage", "must not be negative")
+	v.Check(policy.LatePercentage <= 100, "late_percentage", "must not be more than 100")
+}
+
+// Refund maps the refund entity recorded when a reservation is canceled.
+type Refund struct {
+	ID            int64     `json:"id"`
+	ReservationID int64     `json:"reservation_id"`
+	EmployeeID    *int64    `json:"employee_id"`
+	Amount        float64   `json:"amount"`
+	Percentage    float64   `json:"percentage"`
+	CreatedAt     time.Time `json:"created_at"`
+}
@@ -0,0 +1,403 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/andreshungbz/lab4-database-crud/internal/validator"
+)
+
+var (
+	// PaymentMethods mirrors the payment_method enum in the database.
+	PaymentMethods = []string{"cash", "debit_card", "credit_card"}
+	// ReservationSources mirrors the reservation_source enum in the database.
+	ReservationSources = []string{"direct", "Expedia", "Booking.com"}
+	// ReservationStatuses are the values reservations can be filtered by.
+	ReservationStatuses = []string{"all", "upcoming", "past", "canceled"}
+)
+
+// Reservation maps the reservation entity along with its registrations.
+type Reservation struct {
+	ID             int64          `json:"id"`
+	GuestID        int64          `json:"-"`
+	PassportNumber string         `json:"passport_number"`
+	CheckinDate    Date           `json:"checkin_date"`
+	CheckoutDate   Date           `json:"checkout_date"`
+	PaymentAmount  float64        `json:"payment_amount"`
+	PaymentMethod  string         `json:"payment_method"`
+	Source         string         `json:"source"`
+	Canceled       bool           `json:"canceled"`
+	CreatedAt      time.Time      `json:"created_at"`
+	CheckedInAt    *time.Time     `json:"checked_in_at"`
+	CompletedAt    *time.Time     `json:"completed_at"`
+	Registrations  []Registration `json:"registrations"`
+}
+
+// Registration maps a registration row together with the details of the room
+// and room type it refers to, as returned by fn_get_registrations.
+type Registration struct {
+	HotelID       int64   `json:"hotel_id"`
+	RoomNumber    int     `json:"room_number"`
+	Floor         int     `json:"floor"`
+	StatusCode    string  `json:"status_code"`
+	RoomTypeID    int     `json:"room_type_id"`
+	RoomTypeTitle string  `json:"room_type_title"`
+	BaseRate      float64 `json:"base_rate"`
+	MaxOccupancy  int     `json:"max_occupancy"`
+	BedCount      int     `json:"bed_count"`
+	HasBalcony    bool    `json:"has_balcony"`
+}
+
+// ValidateReservation checks the values needed to book a room of a room type
+// in a hotel.
+func ValidateReservation(v *validator.Validator, reservation *Reservation, hotelID, roomTypeID int) {
+	v.Check(reservation.PassportNumber != "", "passport_number", "must be provided")
+
+	v.Check(hotelID > 0, "hotel_id", "must be a positive integer")
+	v.Check(roomTypeID > 0, "room_type_id", "must be a positive integer")
+
+	v.Check(!reservation.CheckinDate.IsZero(), "checkin_date", "must be provided")
+	v.Check(!reservation.CheckoutDate.IsZero(), "checkout_date", "must be provided")
+	v.Check(reservation.CheckoutDate.Time().After(reservation.CheckinDate.Time()), "checkout_date", "must be after checkin_date")
+
+	v.Check(validator.PermittedValue(reservation.PaymentMethod, PaymentMethods...), "payment_method", "must be one of cash, debit_card, or credit_card")
+	v.Check(validator.PermittedValue(reservation.Source, ReservationSources...), "source", "must be one of direct, Expedia, or Booking.com")
+}
+
+// ReservationFilters holds the values that reservations can be filtered by.
+// Upcoming reservations are active and not yet checked out, past reservations
+// are completed or ended without a check-out, and canceled reservations are
+// only those canceled. An empty passport is ignored.
+type ReservationFilters struct {
+	Passport string
+	Status   string
+}
+
+// ValidateReservationFilters checks the reservation filter values.
+func ValidateReservationFilters(v *validator.Validator, rf ReservationFilters) {
+	v.Check(validator.PermittedValue(rf.Status, ReservationStatuses...), "status", "must be one of all, upcoming, past, or canceled")
+}
+
+// ReservationModel holds a handler to the database and the timeout of its queries
+type ReservationModel struct {
+	DB      *sql.DB
+	Timeout time.Duration
+}
+
+// Insert books an available room of the given room type in the given hotel by
+// creating a reservation and its registration through fn_create_reservation_workflow.
+// The reservation's guest id must already be set.
+func (m ReservationModel) Insert(ctx context.Context, reservation *Reservation, hotelID, roomTypeID int) error {
+	query := `SELECT fn_create_reservation_workflow($1, $2, $3, $4, $5, $6, $7)`
+
+	args := []any{
+		reservation.GuestID,
+		reservation.CheckinDate,
+		reservation.CheckoutDate,
+		reservation.PaymentMethod,
+		reservation.Source,
+		hotelID,
+		roomTypeID,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&reservation.ID)
+	if err != nil {
+		return translateError(err)
+	}
+
+	// read back the values calculated by the database
+
+	query = `
+		SELECT payment_amount, canceled, created_at, checked_in_at, completed_at
+		FROM reservation
+		WHERE id = $1`
+
+	err = m.DB.QueryRowContext(ctx, query, reservation.ID).Scan(
+		&reservation.PaymentAmount,
+		&reservation.Canceled,
+		&reservation.CreatedAt,
+		&reservation.CheckedInAt,
+		&reservation.CompletedAt,
+	)
+	if err != nil {
+		return translateError(err)
+	}
+
+	reservation.Registrations, err = m.getRegistrations(ctx, reservation.ID)
+	return err
+}
+
+// Get reads a reservation's id and returns a Reservation with its registrations.
+func (m ReservationModel) Get(ctx context.Context, id int64) (*Reservation, error) {
+	query := `
+		SELECT
+			r.id,
+			r.guest_id,
+			g.passport_number,
+			r.checkin_date,
+			r.checkout_date,
+			r.payment_amount,
+			r.payment_method,
+			r.source,
+			r.canceled,
+			r.created_at,
+			r.checked_in_at,
+			r.completed_at
+		FROM reservation r
+		JOIN guest g ON g.id = r.guest_id
+		WHERE r.id = $1`
+
+	var reservation Reservation
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, id).Scan(
+		&reservation.ID,
+		&reservation.GuestID,
+		&reservation.PassportNumber,
+		&reservation.CheckinDate,
+		&reservation.CheckoutDate,
+		&reservation.PaymentAmount,
+		&reservation.PaymentMethod,
+		&reservation.Source,
+		&reservation.Canceled,
+		&reservation.CreatedAt,
+		&reservation.CheckedInAt,
+		&reservation.CompletedAt,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, translateError(err)
+		}
+	}
+
+	reservation.Registrations, err = m.getRegistrations(ctx, reservation.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &reservation, nil
+}
+
+// GetAll reads all reservations in the database, filtered by the guest's
+// passport number and the reservation's status.
+func (m ReservationModel) GetAll(ctx context.Context, rf ReservationFilters) ([]*Reservation, error) {
+	query := `
+		SELECT
+			r.id,
+			r.guest_id,
+			g.passport_number,
+			r.checkin_date,
+			r.checkout_date,
+			r.payment_amount,
+			r.payment_method,
+			r.source,
+			r.canceled,
+			r.created_at,
+			r.checked_in_at,
+			r.completed_at
+		FROM reservation r
+		JOIN guest g ON g.id = r.guest_id
+		WHERE ($1 = '' OR g.passport_number = $1)
+			AND ($2 = 'all'
+				OR ($2 = 'upcoming' AND r.canceled = FALSE
+					AND r.completed_at IS NULL AND r.checkout_date > CURRENT_DATE)
+				OR ($2 = 'past' AND r.canceled = FALSE
+					AND (r.completed_at IS NOT NULL OR r.checkout_date <= CURRENT_DATE))
+				OR ($2 = 'canceled' AND r.canceled = TRUE))
+		ORDER BY r.id ASC`
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	// retrieves rows from the database
+	rows, err := m.DB.QueryContext(ctx, query, rf.Passport, rf.Status)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	// construct the array of reservations
+	reservations := []*Reservation{}
+	for rows.Next() {
+		var reservation Reservation
+
+		err := rows.Scan(
+			&reservation.ID,
+			&reservation.GuestID,
+			&reservation.PassportNumber,
+			&reservation.CheckinDate,
+			&reservation.CheckoutDate,
+			&reservation.PaymentAmount,
+			&reservation.PaymentMethod,
+			&reservation.Source,
+			&reservation.Canceled,
+			&reservation.CreatedAt,
+			&reservation.CheckedInAt,
+			&reservation.CompletedAt,
+		)
+		if err != nil {
+			return nil, translateError(err)
+		}
+
+		reservations = append(reservations, &reservation)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, translateError(err)
+	}
+
+	// attach the registrations of each reservation
+	for _, reservation := range reservations {
+		reservation.Registrations, err = m.getRegistrations(ctx, reservation.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return reservations, nil
+}
+
+// CheckIn checks a guest into the rooms of a reservation using
+// fn_check_in_reservation, which sets the rooms to occupied/clean. The status
+// changes are attributed to the employee.
+func (m ReservationModel) CheckIn(ctx context.Context, id int64, employeeID int64) error {
+	return m.execStayFunction(ctx, `SELECT fn_check_in_reservation($1)`, id, employeeID)
+}
+
+// CheckOut completes a reservation using fn_check_out_reservation, which sets
+// the rooms to vacant/dirty and creates their housekeeping tasks. The status
+// changes are attributed to the employee.
+func (m ReservationModel) CheckOut(ctx context.Context, id int64, employeeID int64) error {
+	return m.execStayFunction(ctx, `SELECT fn_check_out_reservation($1)`, id, employeeID)
+}
+
+// Cancel cancels a reservation that has not been checked in before its
+// checkout date using fn_cancel_reservation, and returns the refund owed under
+// the policy. The cancellation is attributed to the employee.
+func (m ReservationModel) Cancel(ctx context.Context, id int64, policy RefundPolicy, employeeID int64) (*Refund, error) {
+	query := `SELECT * FROM fn_cancel_reservation($1, $2, $3, $4)`
+
+	args := []any{id, employeeID, policy.FreeDays, policy.LatePercentage}
+
+	var refund Refund
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(
+		&refund.ID,
+		&refund.ReservationID,
+		&refund.EmployeeID,
+		&refund.Amount,
+		&refund.Percentage,
+		&refund.CreatedAt,
+	)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return &refund, nil
+}
+
+// execStayFunction runs a check-in or check-out function in a transaction
+// attributed to the employee.
+func (m ReservationModel) execStayFunction(ctx context.Context, query string, id int64, employeeID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return translateError(err)
+	}
+	defer tx.Rollback()
+
+	err = setTxEmployee(ctx, tx, employeeID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, query, id)
+	if err != nil {
+		return translateError(err)
+	}
+
+	return tx.Commit()
+}
+
+// Delete removes a reservation from the database along with its registrations.
+func (m ReservationModel) Delete(ctx context.Context, id int64) error {
+	query := `DELETE FROM reservation WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id)
+	if err != nil {
+		return translateError(err)
+	}
+
+	// No rows being affected means the reservation is not in the database
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return translateError(err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// getRegistrations returns the registrations of a reservation using
+// fn_get_registrations.
+func (m ReservationModel) getRegistrations(ctx context.Context, reservationID int64) ([]Registration, error) {
+	query := `SELECT * FROM fn_get_registrations($1)`
+
+	rows, err := m.DB.QueryContext(ctx, query, reservationID)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	registrations := []Registration{}
+	for rows.Next() {
+		var registration Registration
+
+		err := rows.Scan(
+			&registration.HotelID,
+			&registration.RoomNumber,
+			&registration.Floor,
+			&registration.StatusCode,
+			&registration.RoomTypeID,
+			&registration.RoomTypeTitle,
+			&registration.BaseRate,
+			&registration.MaxOccupancy,
+			&registration.BedCount,
+			&registration.HasBalcony,
+		)
+		if err != nil {
+			return nil, translateError(err)
+		}
+
+		registrations = append(registrations, registration)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, translateError(err)
+	}
+
+	return registrations, nil
+}
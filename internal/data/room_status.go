@@ -0,0 +1,269 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/andreshungbz/lab4-database-crud/internal/validator"
+)
+
+// Room status codes mirroring the room_status enum in the database.
+const (
+	RoomVacantClean   = "V/C"
+	RoomOccupiedClean = "O/C"
+	RoomOccupiedDirty = "O/D"
+	RoomVacantDirty   = "V/D"
+)
+
+// RoomStatuses contains every room status code.
+var RoomStatuses = []string{RoomVacantClean, RoomOccupiedClean, RoomOccupiedDirty, RoomVacantDirty}
+
+// roomStatusTransitions maps each room status to the statuses it may change to.
+// Vacant/dirty rooms may only become vacant/clean once their housekeeping tasks
+// are complete and they are not out of order, which RoomStatusModel.Update
+// checks separately.
+var roomStatusTransitions = map[string][]string{
+	RoomVacantClean:   {RoomOccupiedClean, RoomVacantDirty},
+	RoomOccupiedClean: {RoomOccupiedDirty, RoomVacantDirty},
+	RoomOccupiedDirty: {RoomOccupiedClean, RoomVacantDirty},
+	RoomVacantDirty:   {RoomVacantClean},
+}
+
+// ValidRoomStatusTransition reports whether a room may change from one status
+// to another.
+func ValidRoomStatusTransition(from, to string) bool {
+	return validator.PermittedValue(to, roomStatusTransitions[from]...)
+}
+
+// ValidateRoomStatus checks that a status code is one of the room statuses.
+func ValidateRoomStatus(v *validator.Validator, status string) {
+	v.Check(status != "", "status_code", "must be provided")
+	v.Check(validator.PermittedValue(status, RoomStatuses...), "status_code", "must be one of V/C, O/C, O/D, or V/D")
+}
+
+// RoomStatusChange maps a row of the room status history.
+type RoomStatusChange struct {
+	ID             int64     `json:"id"`
+	HotelID        int64     `json:"hotel_id"`
+	RoomNumber     int       `json:"room_number"`
+	EmployeeID     *int64    `json:"employee_id"`
+	PreviousStatus string    `json:"previous_status"`
+	NewStatus      string    `json:"new_status"`
+	ChangedAt      time.Time `json:"changed_at"`
+}
+
+// RoomStatusModel holds a handler to the database and the timeout of its queries
+type RoomStatusModel struct {
+	DB      *sql.DB
+	Timeout time.Duration
+}
+
+// Update changes a room's status on behalf of an employee if the transition is
+// legal, returning the recorded history entry.
+func (m RoomStatusModel) Update(ctx context.Context, hotelID int64, number int, status string, employeeID int64) (*RoomStatusChange, error) {
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer tx.Rollback()
+
+	change, err := updateRoomStatus(ctx, tx, hotelID, number, status, employeeID)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return change, nil
+}
+
+// updateRoomStatus performs a room status change within a transaction so that
+// other workflows can change statuses alongside their own changes.
+func updateRoomStatus(ctx context.Context, tx *sql.Tx, hotelID int64, number int, status string, employeeID int64) (*RoomStatusChange, error) {
+	// lock the room and read its current status
+	query := `
+		SELECT status_code
+		FROM room
+		WHERE hotel_id = $1 AND number = $2
+		FOR UPDATE`
+
+	var current string
+	err := tx.QueryRowContext(ctx, query, hotelID, number).Scan(&current)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRoomNotFound
+		default:
+			return nil, translateError(err)
+		}
+	}
+
+	if !ValidRoomStatusTransition(current, status) {
+		return nil, ErrIllegalStatusTransition
+	}
+
+	// a room can only be clean once all of its housekeeping is done
+	if current == RoomVacantDirty && status == RoomVacantClean {
+		query = `
+			SELECT COUNT(*)
+			FROM housekeeping_task
+			WHERE hotel_id = $1 AND room_number = $2 AND completed_at IS NULL`
+
+		var outstanding int
+		err = tx.QueryRowContext(ctx, query, hotelID, number).Scan(&outstanding)
+		if err != nil {
+			return nil, translateError(err)
+		}
+
+		if outstanding > 0 {
+			return nil, ErrOutstandingTasks
+		}
+
+		// nor while it is out of order
+		outOfOrder, err := roomOutOfOrder(ctx, tx, hotelID, number)
+		if err != nil {
+			return nil, err
+		}
+
+		if outOfOrder {
+			return nil, ErrRoomOutOfOrder
+		}
+	}
+
+	err = setTxEmployee(ctx, tx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	// the trigger on room records the history entry
+	query = `
+		UPDATE room
+		SET status_code = $3
+		WHERE hotel_id = $1 AND number = $2`
+
+	_, err = tx.ExecContext(ctx, query, hotelID, number, status)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	query = `
+		SELECT id, hotel_id, room_number, employee_id, previous_status, new_status, changed_at
+		FROM room_status_history
+		WHERE hotel_id = $1 AND room_number = $2
+		ORDER BY id DESC
+		LIMIT 1`
+
+	var change RoomStatusChange
+	err = tx.QueryRowContext(ctx, query, hotelID, number).Scan(
+		&change.ID,
+		&change.HotelID,
+		&change.RoomNumber,
+		&change.EmployeeID,
+		&change.PreviousStatus,
+		&change.NewStatus,
+		&change.ChangedAt,
+	)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return &change, nil
+}
+
+// GetHistory returns the status changes of a room, most recent first.
+func (m RoomStatusModel) GetHistory(ctx context.Context, hotelID int64, number int) ([]*RoomStatusChange, error) {
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	// distinguish a missing room from a room without history
+	query := `SELECT EXISTS (SELECT 1 FROM room WHERE hotel_id = $1 AND number = $2)`
+
+	var exists bool
+	err := m.DB.QueryRowContext(ctx, query, hotelID, number).Scan(&exists)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	if !exists {
+		return nil, ErrRoomNotFound
+	}
+
+	query = `
+		SELECT id, hotel_id, room_number, employee_id, previous_status, new_status, changed_at
+		FROM room_status_history
+		WHERE hotel_id = $1 AND room_number = $2
+		ORDER BY changed_at DESC, id DESC`
+
+	// retrieves rows from the database
+	rows, err := m.DB.QueryContext(ctx, query, hotelID, number)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	// construct the array of status changes
+	history := []*RoomStatusChange{}
+	for rows.Next() {
+		var change RoomStatusChange
+
+		err := rows.Scan(
+			&change.ID,
+			&change.HotelID,
+			&change.RoomNumber,
+			&change.EmployeeID,
+			&change.PreviousStatus,
+			&change.NewStatus,
+			&change.ChangedAt,
+		)
+		if err != nil {
+			return nil, translateError(err)
+		}
+
+		history = append(history, &change)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, translateError(err)
+	}
+
+	return history, nil
+}
+
+// roomOutOfOrder reports whether a room has an unresolved out-of-order
+// maintenance report.
+func roomOutOfOrder(ctx context.Context, tx *sql.Tx, hotelID int64, number int) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM maintenance_report
+			WHERE hotel_id = $1
+				AND room_number = $2
+				AND out_of_order = TRUE
+				AND completed_at IS NULL)`
+
+	var outOfOrder bool
+	err := tx.QueryRowContext(ctx, query, hotelID, number).Scan(&outOfOrder)
+	if err != nil {
+		return false, translateError(err)
+	}
+
+	return outOfOrder, nil
+}
+
+// setTxEmployee records the employee making changes in a transaction so that
+// triggers can attribute the changes to them.
+func setTxEmployee(ctx context.Context, tx *sql.Tx, employeeID int64) error {
+	query := `SELECT set_config('hotel.employee_id', $1, TRUE)`
+
+	_, err := tx.ExecContext(ctx, query, strconv.FormatInt(employeeID, 10))
+	return translateError(err)
+}
@@ -0,0 +1,25 @@
+package data
+
+import "testing"
+
+func TestValidRoomStatusTransition(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{RoomVacantClean, RoomOccupiedClean, true},
+		{RoomOccupiedClean, RoomOccupiedDirty, true},
+		{RoomOccupiedDirty, RoomVacantDirty, true},
+		{RoomVacantDirty, RoomVacantClean, true},
+		{RoomVacantDirty, RoomOccupiedClean, false},
+		{RoomOccupiedClean, RoomVacantClean, false},
+		{RoomVacantClean, RoomVacantClean, false},
+		{"X/X", RoomVacantClean, false},
+	}
+
+	for _, tt := range tests {
+		if got := ValidRoomStatusTransition(tt.from, tt.to); got != tt.want {
+			t.Errorf("%s to %s: expected %t, got %t", tt.from, tt.to, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/andreshungbz/lab4-database-crud/internal/validator"
+)
+
+// RoomType maps the room_type entity.
+type RoomType struct {
+	ID           int64   `json:"id"`
+	Title        string  `json:"title"`
+	BaseRate     float64 `json:"base_rate"`
+	MaxOccupancy int     `json:"max_occupancy"`
+	BedCount     int     `json:"bed_count"`
+	HasBalcony   bool    `json:"has_balcony"`
+}
+
+// ValidateRoomType checks the title, rate, and capacity of a room type.
+func ValidateRoomType(v *validator.Validator, roomType *RoomType) {
+	v.Check(roomType.Title != "", "title", "must be provided")
+	v.Check(len(roomType.Title) <= 100, "title", "must not be more than 100 bytes long")
+
+	v.Check(roomType.BaseRate > 0, "base_rate", "must be a positive amount")
+	v.Check(roomType.BaseRate < 10_000_000_000, "base_rate", "must be less than 10 billion")
+
+	v.Check(roomType.BedCount > 0, "bed_count", "must be greater than zero")
+	v.Check(roomType.MaxOccupancy >= roomType.BedCount, "max_occupancy", "must be at least the bed count")
+}
+
+// RoomTypeModel holds a handler to the database and the timeout of its queries
+type RoomTypeModel struct {
+	DB      *sql.DB
+	Timeout time.Duration
+}
+
+// Insert creates a record in table room_type.
+func (m RoomTypeModel) Insert(ctx context.Context, roomType *RoomType) error {
+	query := `
+		INSERT INTO room_type (title, base_rate, max_occupancy, bed_count, has_balcony)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id`
+
+	args := []any{roomType.Title, roomType.BaseRate, roomType.MaxOccupancy, roomType.BedCount, roomType.HasBalcony}
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&roomType.ID)
+	return translateError(err)
+}
+
+// Get reads a room type's id and returns a RoomType.
+func (m RoomTypeModel) Get(ctx context.Context, id int64) (*RoomType, error) {
+	query := `
+		SELECT id, title, base_rate, max_occupancy, bed_count, has_balcony
+		FROM room_type
+		WHERE id = $1`
+
+	var roomType RoomType
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, id).Scan(
+		&roomType.ID,
+		&roomType.Title,
+		&roomType.BaseRate,
+		&roomType.MaxOccupancy,
+		&roomType.BedCount,
+		&roomType.HasBalcony,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, translateError(err)
+		}
+	}
+
+	return &roomType, nil
+}
+
+// GetAll reads all room types in the database, ordered by base rate.
+func (m RoomTypeModel) GetAll(ctx context.Context) ([]*RoomType, error) {
+	query := `
+		SELECT id, title, base_rate, max_occupancy, bed_count, has_balcony
+		FROM room_type
+		ORDER BY base_rate ASC, id ASC`
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	// retrieves rows from the database
+	rows, err := m.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	// construct the array of room types
+	roomTypes := []*RoomType{}
+	for rows.Next() {
+		var roomType RoomType
+
+		err := rows.Scan(
+			&roomType.ID,
+			&roomType.Title,
+			&roomType.BaseRate,
+			&roomType.MaxOccupancy,
+			&roomType.BedCount,
+			&roomType.HasBalcony,
+		)
+		if err != nil {
+			return nil, translateError(err)
+		}
+
+		roomTypes = append(roomTypes, &roomType)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, translateError(err)
+	}
+
+	return roomTypes, nil
+}
+
+// Update modifies a room_type record.
+func (m RoomTypeModel) Update(ctx context.Context, roomType *RoomType) error {
+	query := `
+		UPDATE room_type
+		SET title = $1, base_rate = $2, max_occupancy = $3, bed_count = $4, has_balcony = $5
+		WHERE id = $6`
+
+	args := []any{roomType.Title, roomType.BaseRate, roomType.MaxOccupancy, roomType.BedCount, roomType.HasBalcony, roomType.ID}
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, args...)
+	if err != nil {
+		return translateError(err)
+	}
+
+	// No rows being affected means the room type is not in the database
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return translateError(err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// Delete removes a room type from the database along with the rooms of that type.
+func (m RoomTypeModel) Delete(ctx context.Context, id int64) error {
+	query := `DELETE FROM room_type WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id)
+	if err != nil {
+		return translateError(err)
+	}
+
+	// No rows being affected means the room type is not in the database
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return translateError(err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,221 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/andreshungbz/lab4-database-crud/internal/validator"
+)
+
+// Room maps the room entity. Rooms are identified by their hotel and number,
+// where the hundreds of the number are the floor (e.g. room 302 is on floor 3).
+type Room struct {
+	HotelID    int64  `json:"hotel_id"`
+	Number     int    `json:"number"`
+	RoomTypeID int64  `json:"room_type_id"`
+	Floor      int    `json:"floor"`
+	StatusCode string `json:"status_code"`
+}
+
+// ValidateRoom checks the number, floor, and room type of a room.
+func ValidateRoom(v *validator.Validator, room *Room) {
+	v.Check(room.Number > 0, "number", "must be a positive integer")
+	v.Check(room.RoomTypeID > 0, "room_type_id", "must be a positive integer")
+
+	v.Check(room.Floor > 0, "floor", "must be a positive integer")
+	v.Check(room.Number/100 == room.Floor, "floor", "must match the hundreds of the room number")
+}
+
+// RoomFilters holds the values that rooms can be filtered by. Zero values and
+// empty strings are ignored.
+type RoomFilters struct {
+	Floor      int
+	RoomTypeID int64
+	StatusCode string
+}
+
+// ValidateRoomFilters checks the room filter values.
+func ValidateRoomFilters(v *validator.Validator, rf RoomFilters) {
+	v.Check(rf.Floor >= 0, "floor", "must not be negative")
+	v.Check(rf.RoomTypeID >= 0, "room_type_id", "must not be negative")
+
+	if rf.StatusCode != "" {
+		v.Check(validator.PermittedValue(rf.StatusCode, RoomStatuses...), "status_code", "must be one of V/C, O/C, O/D, or V/D")
+	}
+}
+
+// RoomModel holds a handler to the database and the timeout of its queries
+type RoomModel struct {
+	DB      *sql.DB
+	Timeout time.Duration
+}
+
+// Insert creates a record in table room. New rooms are vacant/clean.
+func (m RoomModel) Insert(ctx context.Context, room *Room) error {
+	// selecting from hotel distinguishes a missing hotel from a missing room type
+	query := `
+		INSERT INTO room (hotel_id, number, room_type_id, floor)
+		SELECT h.id, $2, $3, $4
+		FROM hotel h
+		WHERE h.id = $1
+		RETURNING status_code`
+
+	args := []any{room.HotelID, room.Number, room.RoomTypeID, room.Floor}
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&room.StatusCode)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrHotelNotFound
+		default:
+			return translateError(err)
+		}
+	}
+
+	return nil
+}
+
+// Get reads a room's hotel id and number and returns a Room.
+func (m RoomModel) Get(ctx context.Context, hotelID int64, number int) (*Room, error) {
+	query := `
+		SELECT hotel_id, number, room_type_id, floor, status_code
+		FROM room
+		WHERE hotel_id = $1 AND number = $2`
+
+	var room Room
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, hotelID, number).Scan(
+		&room.HotelID,
+		&room.Number,
+		&room.RoomTypeID,
+		&room.Floor,
+		&room.StatusCode,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRoomNotFound
+		default:
+			return nil, translateError(err)
+		}
+	}
+
+	return &room, nil
+}
+
+// GetAll reads all rooms of a hotel, filtered by floor, room type, and status.
+func (m RoomModel) GetAll(ctx context.Context, hotelID int64, rf RoomFilters) ([]*Room, error) {
+	query := `
+		SELECT hotel_id, number, room_type_id, floor, status_code
+		FROM room
+		WHERE hotel_id = $1
+			AND ($2 = 0 OR floor = $2)
+			AND ($3 = 0 OR room_type_id = $3)
+			AND ($4 = '' OR status_code::text = $4)
+		ORDER BY number ASC`
+
+	args := []any{hotelID, rf.Floor, rf.RoomTypeID, rf.StatusCode}
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	// retrieves rows from the database
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	// construct the array of rooms
+	rooms := []*Room{}
+	for rows.Next() {
+		var room Room
+
+		err := rows.Scan(
+			&room.HotelID,
+			&room.Number,
+			&room.RoomTypeID,
+			&room.Floor,
+			&room.StatusCode,
+		)
+		if err != nil {
+			return nil, translateError(err)
+		}
+
+		rooms = append(rooms, &room)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, translateError(err)
+	}
+
+	return rooms, nil
+}
+
+// Update modifies a room's type and floor. Status changes go through
+// RoomStatusModel so that transitions are enforced.
+func (m RoomModel) Update(ctx context.Context, room *Room) error {
+	query := `
+		UPDATE room
+		SET room_type_id = $1, floor = $2
+		WHERE hotel_id = $3 AND number = $4`
+
+	args := []any{room.RoomTypeID, room.Floor, room.HotelID, room.Number}
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, args...)
+	if err != nil {
+		return translateError(err)
+	}
+
+	// No rows being affected means the room is not in the database
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return translateError(err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrRoomNotFound
+	}
+
+	return nil
+}
+
+// Delete removes a room from the database along with its registrations and
+// housekeeping records.
+func (m RoomModel) Delete(ctx context.Context, hotelID int64, number int) error {
+	query := `DELETE FROM room WHERE hotel_id = $1 AND number = $2`
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, hotelID, number)
+	if err != nil {
+		return translateError(err)
+	}
+
+	// No rows being affected means the room is not in the database
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return translateError(err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrRoomNotFound
+	}
+
+	return nil
+}
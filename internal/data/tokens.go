@@ -0,0 +1,88 @@
+package data
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"time"
+
+	"github.com/andreshungbz/lab4-database-crud/internal/validator"
+)
+
+const (
+	ScopeAuthentication = "authentication"
+)
+
+// Token maps a session token belonging to an employee. Only the hash is stored
+// in the database; the plaintext is given to the client once.
+type Token struct {
+	Plaintext  string    `json:"token"`
+	Hash       []byte    `json:"-"`
+	EmployeeID int64     `json:"-"`
+	Expiry     time.Time `json:"expiry"`
+	Scope      string    `json:"-"`
+}
+
+// generateToken creates a Token with a random plaintext value and its hash.
+func generateToken(employeeID int64, ttl time.Duration, scope string) *Token {
+	token := &Token{
+		Plaintext:  rand.Text(),
+		EmployeeID: employeeID,
+		Expiry:     time.Now().Add(ttl),
+		Scope:      scope,
+	}
+
+	hash := sha256.Sum256([]byte(token.Plaintext))
+	token.Hash = hash[:]
+
+	return token
+}
+
+// ValidateTokenPlaintext checks that a token has the length of a generated token.
+func ValidateTokenPlaintext(v *validator.Validator, tokenPlaintext string) {
+	v.Check(tokenPlaintext != "", "token", "must be provided")
+	v.Check(len(tokenPlaintext) == 26, "token", "must be 26 bytes long")
+}
+
+// TokenModel holds a handler to the database and the timeout of its queries
+type TokenModel struct {
+	DB      *sql.DB
+	Timeout time.Duration
+}
+
+// New generates a token for an employee and inserts it into the database.
+func (m TokenModel) New(ctx context.Context, employeeID int64, ttl time.Duration, scope string) (*Token, error) {
+	token := generateToken(employeeID, ttl, scope)
+
+	err := m.Insert(ctx, token)
+	return token, err
+}
+
+// Insert creates a record in table tokens.
+func (m TokenModel) Insert(ctx context.Context, token *Token) error {
+	query := `
+		INSERT INTO tokens (hash, employee_id, expiry, scope)
+		VALUES ($1, $2, $3, $4)`
+
+	args := []any{token.Hash, token.EmployeeID, token.Expiry, token.Scope}
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, args...)
+	return translateError(err)
+}
+
+// DeleteAllForEmployee removes all of an employee's tokens of a scope.
+func (m TokenModel) DeleteAllForEmployee(ctx context.Context, scope string, employeeID int64) error {
+	query := `
+		DELETE FROM tokens
+		WHERE scope = $1 AND employee_id = $2`
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, scope, employeeID)
+	return translateError(err)
+}
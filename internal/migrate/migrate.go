@@ -0,0 +1,366 @@
+// Package migrate applies SQL migrations embedded in the program. The schema
+// version is kept in a schema_migrations table and guarded by the same advisory
+// lock as the golang-migrate CLI, so either can be used on the same database.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io/fs"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// NilVersion is the version of a database without any applied migrations.
+const NilVersion = -1
+
+var (
+	ErrDirty          = errors.New("database is dirty")
+	ErrUnknownVersion = errors.New("unknown migration version")
+	ErrNoDownFile     = errors.New("migration has no down file")
+)
+
+// advisoryLockSalt is multiplied with the checksum of the database, schema,
+// and table names to create the advisory lock id, as golang-migrate does.
+const advisoryLockSalt uint32 = 1486364155
+
+// migrationFileRX matches the golang-migrate file name format.
+var migrationFileRX = regexp.MustCompile(`^([0-9]+)_(.*)\.(up|down)\.sql$`)
+
+// Migration is a version of the schema with the SQL to migrate up to it and
+// back down from it.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// step applies the SQL of one migration and records the resulting version.
+type step struct {
+	version   int // the version after the step
+	migration Migration
+	up        bool
+}
+
+// Migrator applies migrations to a database. Progress is logged to Logger if
+// it is set.
+type Migrator struct {
+	DB         *sql.DB
+	Logger     *slog.Logger
+	migrations []Migration // sorted by version
+}
+
+// New reads the migration files in the root of fsys and returns a Migrator for
+// the database.
+func New(db *sql.DB, fsys fs.FS) (*Migrator, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		matches := migrationFileRX.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || matches == nil {
+			continue
+		}
+
+		version, err := strconv.Atoi(matches[1])
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: %w", entry.Name(), err)
+		}
+
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: matches[2]}
+			byVersion[version] = migration
+		}
+
+		if migration.Name != matches[2] {
+			return nil, fmt.Errorf("migration %d has files named %s and %s", version, migration.Name, matches[2])
+		}
+
+		if matches[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	m := &Migrator{DB: db}
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", migration.Version, migration.Name)
+		}
+		m.migrations = append(m.migrations, *migration)
+	}
+
+	slices.SortFunc(m.migrations, func(a, b Migration) int {
+		return a.Version - b.Version
+	})
+
+	return m, nil
+}
+
+// Migrations returns the migrations sorted by version.
+func (m *Migrator) Migrations() []Migration {
+	return m.migrations
+}
+
+// Latest returns the version of the last migration.
+func (m *Migrator) Latest() int {
+	if len(m.migrations) == 0 {
+		return NilVersion
+	}
+
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Version returns the current version of the database and whether a migration
+// to it failed part way.
+func (m *Migrator) Version(ctx context.Context) (int, bool, error) {
+	conn, err := m.DB.Conn(ctx)
+	if err != nil {
+		return 0, false, err
+	}
+	defer conn.Close()
+
+	return version(ctx, conn)
+}
+
+// Up applies the next n migrations, or all of them if n is not positive.
+func (m *Migrator) Up(ctx context.Context, n int) error {
+	return m.run(ctx, func(current int) int {
+		i := m.index(current) + n
+		if n <= 0 || i >= len(m.migrations) {
+			return m.Latest()
+		}
+		return m.migrations[i].Version
+	})
+}
+
+// Down reverts the last n applied migrations, or all of them if n is not
+// positive.
+func (m *Migrator) Down(ctx context.Context, n int) error {
+	return m.run(ctx, func(current int) int {
+		i := m.index(current) - n
+		if n <= 0 || i < 0 {
+			return NilVersion
+		}
+		return m.migrations[i].Version
+	})
+}
+
+// Goto migrates up or down to a version.
+func (m *Migrator) Goto(ctx context.Context, target int) error {
+	if target != NilVersion && m.index(target) == -1 {
+		return fmt.Errorf("%w: %d", ErrUnknownVersion, target)
+	}
+
+	return m.run(ctx, func(int) int {
+		return target
+	})
+}
+
+// Force sets the version of the database without running any migrations and
+// marks it clean. It is used to recover from a failed migration once the
+// database has been fixed by hand.
+func (m *Migrator) Force(ctx context.Context, target int) error {
+	if target != NilVersion && m.index(target) == -1 {
+		return fmt.Errorf("%w: %d", ErrUnknownVersion, target)
+	}
+
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		return setVersion(ctx, conn, target, false)
+	})
+}
+
+// run migrates the database from its current version to the version returned
+// by target, one migration at a time.
+func (m *Migrator) run(ctx context.Context, target func(current int) int) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		current, dirty, err := version(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		if dirty {
+			return fmt.Errorf("%w at version %d, fix it and force the version", ErrDirty, current)
+		}
+
+		if current != NilVersion && m.index(current) == -1 {
+			return fmt.Errorf("%w: database is at version %d", ErrUnknownVersion, current)
+		}
+
+		steps, err := m.plan(current, target(current))
+		if err != nil {
+			return err
+		}
+
+		for _, s := range steps {
+			query, direction := s.migration.Up, "up"
+			if !s.up {
+				query, direction = s.migration.Down, "down"
+			}
+
+			if m.Logger != nil {
+				m.Logger.Info("Applying migration", "version", s.migration.Version, "name", s.migration.Name, "direction", direction)
+			}
+
+			// the version stays dirty if the migration fails
+			err := setVersion(ctx, conn, s.version, true)
+			if err != nil {
+				return err
+			}
+
+			_, err = conn.ExecContext(ctx, query)
+			if err != nil {
+				return fmt.Errorf("migration %d_%s %s: %w", s.migration.Version, s.migration.Name, direction, err)
+			}
+
+			err = setVersion(ctx, conn, s.version, false)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// plan returns the steps that migrate from the current version to the target
+// version.
+func (m *Migrator) plan(current, target int) ([]step, error) {
+	var steps []step
+
+	// migrate up through the versions after current
+	for i := m.index(current) + 1; target > current && i < len(m.migrations) && m.migrations[i].Version <= target; i++ {
+		steps = append(steps, step{version: m.migrations[i].Version, migration: m.migrations[i], up: true})
+	}
+
+	// migrate down through the versions after target, the last first
+	for i := m.index(current); target < current && i >= 0 && m.migrations[i].Version > target; i-- {
+		migration := m.migrations[i]
+		if migration.Down == "" {
+			return nil, fmt.Errorf("%w: %d_%s", ErrNoDownFile, migration.Version, migration.Name)
+		}
+
+		previous := NilVersion
+		if i > 0 {
+			previous = m.migrations[i-1].Version
+		}
+
+		steps = append(steps, step{version: previous, migration: migration, up: false})
+	}
+
+	return steps, nil
+}
+
+// index returns the position of a version in the migrations, or -1 if it is not
+// one of them.
+func (m *Migrator) index(version int) int {
+	return slices.IndexFunc(m.migrations, func(migration Migration) bool {
+		return migration.Version == version
+	})
+}
+
+// withLock runs fn on a single connection while holding the advisory lock,
+// creating the schema_migrations table if it does not exist.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.DB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var database, schema string
+	err = conn.QueryRowContext(ctx, `SELECT current_database(), current_schema()`).Scan(&database, &schema)
+	if err != nil {
+		return err
+	}
+
+	// advisory locks belong to the session, so they are taken and released on
+	// the same connection
+	id := advisoryLockID(database, schema, "schema_migrations")
+
+	_, err = conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, id)
+	if err != nil {
+		return err
+	}
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, id)
+
+	query := `CREATE TABLE IF NOT EXISTS schema_migrations (version bigint NOT NULL PRIMARY KEY, dirty boolean NOT NULL)`
+	_, err = conn.ExecContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+// advisoryLockID returns the advisory lock id that golang-migrate uses for the
+// migrations table of a database.
+func advisoryLockID(database, schema, table string) int64 {
+	name := strings.Join([]string{schema, table, database}, "\x00")
+	return int64(crc32.ChecksumIEEE([]byte(name)) * advisoryLockSalt)
+}
+
+// version reads the version and dirty flag from schema_migrations. A missing
+// table or row means no migrations have been applied.
+func version(ctx context.Context, conn *sql.Conn) (int, bool, error) {
+	var version int
+	var dirty bool
+
+	err := conn.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if err != nil {
+		var pqErr *pq.Error
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return NilVersion, false, nil
+		case errors.As(err, &pqErr) && pqErr.Code.Name() == "undefined_table":
+			return NilVersion, false, nil
+		default:
+			return 0, false, err
+		}
+	}
+
+	return version, dirty, nil
+}
+
+// setVersion replaces the row of schema_migrations. The nil version is only
+// recorded if it is dirty.
+func setVersion(ctx context.Context, conn *sql.Conn, version int, dirty bool) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `TRUNCATE schema_migrations`)
+	if err != nil {
+		return err
+	}
+
+	if version != NilVersion || dirty {
+		_, err = tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, dirty) VALUES ($1, $2)`, version, dirty)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,117 @@
+package migrate
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/andreshungbz/lab4-database-crud/migrations"
+)
+
+func TestNew(t *testing.T) {
+	fsys := fstest.MapFS{
+		"000002_add_index.up.sql":      {Data: []byte("CREATE INDEX ...")},
+		"000002_add_index.down.sql":    {Data: []byte("DROP INDEX ...")},
+		"000001_create_table.up.sql":   {Data: []byte("CREATE TABLE ...")},
+		"000001_create_table.down.sql": {Data: []byte("DROP TABLE ...")},
+		"000003_seed.up.sql":           {Data: []byte("INSERT ...")},
+		"README.md":                    {Data: []byte("not a migration")},
+	}
+
+	m, err := New(nil, fsys)
+	if err != nil {
+		t.Fatalf("New error: %v", err)
+	}
+
+	migrations := m.Migrations()
+	if len(migrations) != 3 {
+		t.Fatalf("expected 3 migrations, got %d", len(migrations))
+	}
+
+	// assert the migrations are sorted and paired
+	for i, name := range []string{"create_table", "add_index", "seed"} {
+		if migrations[i].Version != i+1 || migrations[i].Name != name {
+			t.Errorf("migration %d: got %d_%s", i, migrations[i].Version, migrations[i].Name)
+		}
+	}
+	if migrations[1].Down != "DROP INDEX ..." {
+		t.Errorf("expected down file of add_index, got %q", migrations[1].Down)
+	}
+	if m.Latest() != 3 {
+		t.Errorf("expected latest version 3, got %d", m.Latest())
+	}
+
+	// a down file without an up file is rejected
+	_, err = New(nil, fstest.MapFS{"000001_create_table.down.sql": {Data: []byte("DROP TABLE ...")}})
+	if err == nil {
+		t.Error("expected error for a migration without an up file")
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	m, err := New(nil, migrations.Files)
+	if err != nil {
+		t.Fatalf("New error: %v", err)
+	}
+
+	for i, migration := range m.Migrations() {
+		if migration.Version != i+1 {
+			t.Errorf("expected version %d, got %d", i+1, migration.Version)
+		}
+		if migration.Down == "" {
+			t.Errorf("migration %d_%s has no down file", migration.Version, migration.Name)
+		}
+	}
+}
+
+func TestPlan(t *testing.T) {
+	m := &Migrator{migrations: []Migration{
+		{Version: 1, Name: "one", Up: "1 up", Down: "1 down"},
+		{Version: 2, Name: "two", Up: "2 up", Down: "2 down"},
+		{Version: 3, Name: "three", Up: "3 up"},
+	}}
+
+	type want struct {
+		version int
+		up      bool
+	}
+
+	tests := []struct {
+		name            string
+		current, target int
+		want            []want
+		wantErr         error
+	}{
+		{"all up", NilVersion, 3, []want{{1, true}, {2, true}, {3, true}}, nil},
+		{"partly up", 1, 2, []want{{2, true}}, nil},
+		{"no change", 2, 2, nil, nil},
+		{"all down", 2, NilVersion, []want{{1, false}, {NilVersion, false}}, nil},
+		{"missing down file", 3, 2, nil, ErrNoDownFile},
+	}
+
+	for _, tt := range tests {
+		steps, err := m.plan(tt.current, tt.target)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: expected error %v, got %v", tt.name, tt.wantErr, err)
+			continue
+		}
+
+		if len(steps) != len(tt.want) {
+			t.Errorf("%s: expected %d steps, got %d", tt.name, len(tt.want), len(steps))
+			continue
+		}
+
+		for i, s := range steps {
+			if s.version != tt.want[i].version || s.up != tt.want[i].up {
+				t.Errorf("%s: step %d: expected %+v, got version %d up %t", tt.name, i, tt.want[i], s.version, s.up)
+			}
+		}
+	}
+}
+
+func TestAdvisoryLockID(t *testing.T) {
+	// the id golang-migrate takes for the hotel database
+	if id := advisoryLockID("hotel", "public", "schema_migrations"); id != 1706163785 {
+		t.Errorf("expected 1706163785, got %d", id)
+	}
+}
@@ -0,0 +1,146 @@
+// Package password contains functions for hashing and verifying employee
+// passwords. New hashes use argon2id and are stored in the PHC string format,
+// which records the algorithm and its parameters. Unsalted SHA-256 digests from
+// the seed data are still verified so that they can be upgraded on login.
+package password
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Algorithm names as reported by Algorithm.
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmSHA256   = "sha256"
+)
+
+var (
+	ErrUnknownAlgorithm = errors.New("password hash uses an unknown algorithm")
+	ErrMalformedHash    = errors.New("password hash is malformed")
+)
+
+// params holds the argon2id cost parameters.
+type params struct {
+	memory  uint32 // KiB
+	time    uint32
+	threads uint8
+	saltLen uint32
+	keyLen  uint32
+}
+
+// current holds the parameters used for new hashes. Hashes with different
+// parameters are reported as needing a rehash.
+var current = params{
+	memory:  64 * 1024,
+	time:    1,
+	threads: 4,
+	saltLen: 16,
+	keyLen:  32,
+}
+
+// Hash returns the argon2id hash of a plaintext password in the form
+// $argon2id$v=19$m=65536,t=1,p=4$<salt>$<key>.
+func Hash(plaintext string) ([]byte, error) {
+	salt := make([]byte, current.saltLen)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return nil, err
+	}
+
+	key := argon2.IDKey([]byte(plaintext), salt, current.time, current.memory, current.threads, current.keyLen)
+
+	encoded := fmt.Sprintf("$%s$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		AlgorithmArgon2id,
+		argon2.Version,
+		current.memory,
+		current.time,
+		current.threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	)
+
+	return []byte(encoded), nil
+}
+
+// Algorithm returns the name of the algorithm that produced a stored hash.
+func Algorithm(hash []byte) string {
+	switch {
+	case strings.HasPrefix(string(hash), "$"+AlgorithmArgon2id+"$"):
+		return AlgorithmArgon2id
+	case len(hash) == sha256.Size:
+		return AlgorithmSHA256
+	default:
+		return ""
+	}
+}
+
+// Verify reports whether a plaintext password matches a stored hash, and
+// whether the hash should be replaced with one from Hash because it uses a
+// legacy algorithm or outdated parameters.
+func Verify(plaintext string, hash []byte) (match bool, needsRehash bool, err error) {
+	switch Algorithm(hash) {
+	case AlgorithmArgon2id:
+		p, salt, key, err := decodeArgon2id(hash)
+		if err != nil {
+			return false, false, err
+		}
+
+		otherKey := argon2.IDKey([]byte(plaintext), salt, p.time, p.memory, p.threads, p.keyLen)
+		match = subtle.ConstantTimeCompare(key, otherKey) == 1
+
+		return match, match && p != current, nil
+
+	case AlgorithmSHA256:
+		digest := sha256.Sum256([]byte(plaintext))
+		match = subtle.ConstantTimeCompare(digest[:], hash) == 1
+
+		return match, match, nil
+
+	default:
+		return false, false, ErrUnknownAlgorithm
+	}
+}
+
+// decodeArgon2id parses the parameters, salt, and key of an argon2id hash.
+func decodeArgon2id(hash []byte) (params, []byte, []byte, error) {
+	var p params
+
+	// "", "argon2id", "v=19", "m=...,t=...,p=...", salt, key
+	parts := strings.Split(string(hash), "$")
+	if len(parts) != 6 {
+		return p, nil, nil, ErrMalformedHash
+	}
+
+	var version int
+	_, err := fmt.Sscanf(parts[2], "v=%d", &version)
+	if err != nil || version != argon2.Version {
+		return p, nil, nil, ErrMalformedHash
+	}
+
+	_, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.memory, &p.time, &p.threads)
+	if err != nil {
+		return p, nil, nil, ErrMalformedHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return p, nil, nil, ErrMalformedHash
+	}
+	p.saltLen = uint32(len(salt))
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return p, nil, nil, ErrMalformedHash
+	}
+	p.keyLen = uint32(len(key))
+
+	return p, salt, key, nil
+}
@@ -0,0 +1,55 @@
+package password
+
+import (
+	"crypto/sha256"
+	"testing"
+)
+
+func TestHashAndVerify(t *testing.T) {
+	hash, err := Hash("correct horse battery")
+	if err != nil {
+		t.Fatalf("Hash error: %v", err)
+	}
+
+	if algorithm := Algorithm(hash); algorithm != AlgorithmArgon2id {
+		t.Errorf("expected algorithm %s, got %q", AlgorithmArgon2id, algorithm)
+	}
+
+	// assert the correct password matches without needing a rehash
+	match, needsRehash, err := Verify("correct horse battery", hash)
+	if err != nil || !match || needsRehash {
+		t.Errorf("expected match without rehash, got match=%t needsRehash=%t err=%v", match, needsRehash, err)
+	}
+
+	// assert an incorrect password does not match
+	match, _, err = Verify("wrong password", hash)
+	if err != nil || match {
+		t.Errorf("expected no match, got match=%t err=%v", match, err)
+	}
+}
+
+func TestVerifyLegacySHA256(t *testing.T) {
+	digest := sha256.Sum256([]byte("hotel_password"))
+
+	if algorithm := Algorithm(digest[:]); algorithm != AlgorithmSHA256 {
+		t.Errorf("expected algorithm %s, got %q", AlgorithmSHA256, algorithm)
+	}
+
+	// assert legacy digests match and always need a rehash
+	match, needsRehash, err := Verify("hotel_password", digest[:])
+	if err != nil || !match || !needsRehash {
+		t.Errorf("expected match with rehash, got match=%t needsRehash=%t err=%v", match, needsRehash, err)
+	}
+
+	match, needsRehash, err = Verify("wrong password", digest[:])
+	if err != nil || match || needsRehash {
+		t.Errorf("expected no match, got match=%t needsRehash=%t err=%v", match, needsRehash, err)
+	}
+}
+
+func TestVerifyUnknownAlgorithm(t *testing.T) {
+	_, _, err := Verify("hotel_password", []byte("$2a$12$notsupported"))
+	if err != ErrUnknownAlgorithm {
+		t.Errorf("expected ErrUnknownAlgorithm, got %v", err)
+	}
+}
@@ -8,6 +8,19 @@ import (
 var (
 	// https://html.spec.whatwg.org/#valid-e-mail-address
 	EmailRX = regexp.MustCompile("^[a-zA-Z0-9.!#$%&'*+/=?^_`{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$")
+
+	// E.164-style phone number of 7 to 15 digits, optionally separated by
+	// single spaces or dashes
+	PhoneRX = regexp.MustCompile(`^\+?[0-9](?:[ -]?[0-9]){6,14}$`)
+
+	// United States social security number
+	SSNRX = regexp.MustCompile(`^\d{3}-\d{2}-\d{4}$`)
+
+	// character classes for password strength
+	LowercaseRX = regexp.MustCompile(`\p{Ll}`)
+	UppercaseRX = regexp.MustCompile(`\p{Lu}`)
+	DigitRX     = regexp.MustCompile(`\p{Nd}`)
+	SymbolRX    = regexp.MustCompile(`[^\p{L}\p{Nd}\s]`)
 )
 
 // Validator holds multiple errors for validating JSON values to enforce
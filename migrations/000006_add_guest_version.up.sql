@@ -0,0 +1,164 @@
+-- migrations/000006_add_guest_version.up.sql
+-- Adds a version column to guest for optimistic concurrency control and recreates the
+-- guest functions to read, return, and check it.
+
+ALTER TABLE guest ADD COLUMN version INT NOT NULL DEFAULT 1;
+
+-- the return types and parameters change, so the old functions must be dropped first
+DROP FUNCTION IF EXISTS fn_update_guest(TEXT, CITEXT, TEXT, TEXT, TEXT, TEXT, TEXT, TEXT);
+DROP FUNCTION IF EXISTS fn_get_guest(TEXT);
+DROP FUNCTION IF EXISTS fn_create_guest(TEXT, CITEXT, TEXT, TEXT, TEXT, TEXT, TEXT, TEXT);
+
+-- ====================================================================================
+-- CREATE FUNCTION fn_create_guest returns the person id, created_at, and version for a
+-- newly created guest from on the passed guest and person details.
+-- ====================================================================================
+
+CREATE OR REPLACE FUNCTION fn_create_guest(
+    -- guest attributes
+    p_passport TEXT,
+    p_contact_email CITEXT,
+    p_contact_phone TEXT,
+    -- person attributes
+    p_name TEXT,
+    p_gender TEXT,
+    p_street TEXT,
+    p_city TEXT,
+    p_country TEXT
+)
+RETURNS TABLE (
+    id BIGINT,
+    created_at TIMESTAMP(0) WITH TIME ZONE,
+    version INT
+)
+AS $$
+DECLARE
+    v_guest_id BIGINT;
+BEGIN
+    -- insert person entry
+    INSERT INTO person (name, gender, street, city, country)
+    VALUES (p_name, p_gender, p_street, p_city, p_country)
+    RETURNING person.id INTO v_guest_id;
+
+    -- insert guest entry
+    INSERT INTO guest (id, passport_number, contact_email, contact_phone)
+    VALUES (v_guest_id, p_passport, p_contact_email, p_contact_phone);
+
+    RETURN QUERY
+    SELECT
+        p.id,
+        p.created_at,
+        g.version
+    FROM person p
+    JOIN guest g ON g.id = p.id
+    WHERE p.id = v_guest_id;
+END;
+$$ LANGUAGE plpgsql;
+
+-- ====================================================================================
+-- READ FUNCTION fn_get_guest returns the guest and person data for an existing guest by
+-- their passport.
+-- ====================================================================================
+
+CREATE OR REPLACE FUNCTION fn_get_guest(
+    p_passport TEXT
+)
+RETURNS TABLE (
+    id BIGINT,
+    passport_number TEXT,
+    contact_email CITEXT,
+    contact_phone TEXT,
+    name TEXT,
+    gender TEXT,
+    street TEXT,
+    city TEXT,
+    country TEXT,
+    created_at TIMESTAMP(0) WITH TIME ZONE,
+    version INT
+)
+AS $$
+BEGIN
+    RETURN QUERY
+    SELECT
+        g.id,
+        g.passport_number,
+        g.contact_email,
+        g.contact_phone,
+        p.name,
+        p.gender,
+        p.street,
+        p.city,
+        p.country,
+        p.created_at,
+        g.version
+    FROM guest g
+    JOIN person p ON p.id = g.id
+    WHERE g.passport_number = p_passport;
+END;
+$$ LANGUAGE plpgsql;
+
+-- ====================================================================================
+-- UPDATE FUNCTION fn_update_guest updates person and guest details based on passport
+-- number only if the guest is still at the expected version. The new version is returned.
+-- ====================================================================================
+
+CREATE OR REPLACE FUNCTION fn_update_guest(
+    p_passport TEXT,
+    p_version INT,
+    -- guest attributes
+    p_contact_email CITEXT,
+    p_contact_phone TEXT,
+    -- person attributes
+    p_name TEXT,
+    p_gender TEXT,
+    p_street TEXT,
+    p_city TEXT,
+    p_country TEXT
+)
+RETURNS INT
+AS $$
+DECLARE
+    v_guest_id BIGINT;
+    v_version INT;
+BEGIN
+    -- find guest id from passport
+    SELECT g.id
+    INTO v_guest_id
+    FROM guest g
+    WHERE g.passport_number = p_passport;
+
+    IF NOT FOUND THEN
+        RAISE EXCEPTION
+            '[guest-not-found] Guest with passport % does not exist',
+            p_passport;
+    END IF;
+
+    -- update guest only if nobody else has modified it since it was read
+    UPDATE guest
+    SET
+        contact_email = p_contact_email,
+        contact_phone = p_contact_phone,
+        version = guest.version + 1
+    WHERE id = v_guest_id
+        AND guest.version = p_version
+    RETURNING guest.version INTO v_version;
+
+    IF NOT FOUND THEN
+        RAISE EXCEPTION
+            '[edit-conflict] Guest with passport % is no longer at version %',
+            p_passport, p_version;
+    END IF;
+
+    -- update person
+    UPDATE person
+    SET
+        name = p_name,
+        gender = p_gender,
+        street = p_street,
+        city = p_city,
+        country = p_country
+    WHERE id = v_guest_id;
+
+    RETURN v_version;
+END;
+$$ LANGUAGE plpgsql;
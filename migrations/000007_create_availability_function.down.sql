@@ -0,0 +1,9 @@
+-- migrations/000007_create_availability_function.down.sql
+-- Drops the room availability function.
+
+DROP FUNCTION IF EXISTS fn_get_availability(
+    INT,
+    DATE,
+    DATE,
+    INT
+);
@@ -0,0 +1,65 @@
+-- migrations/000007_create_availability_function.up.sql
+-- Creates a function for searching room availability in a hotel.
+
+-- ====================================================================================
+-- READ FUNCTION fn_get_availability returns, for every room type in a hotel that can hold
+-- the number of guests, the count of rooms that fn_find_available_room would consider
+-- available for the date range, along with the total price of the stay.
+-- ====================================================================================
+
+CREATE OR REPLACE FUNCTION fn_get_availability(
+    p_hotel_id INT,
+    p_checkin DATE,
+    p_checkout DATE,
+    p_guests INT
+)
+RETURNS TABLE (
+    room_type_id INT,
+    title TEXT,
+    available_rooms BIGINT,
+    base_rate NUMERIC(12, 2),
+    total_price NUMERIC,
+    max_occupancy INT,
+    bed_count INT,
+    has_balcony BOOLEAN
+)
+AS $$
+BEGIN
+    -- handle non-existent hotels
+    PERFORM 1 FROM hotel h WHERE h.id = p_hotel_id;
+
+    IF NOT FOUND THEN
+        RAISE EXCEPTION '[hotel-not-found] Hotel % does not exist', p_hotel_id;
+    END IF;
+
+    RETURN QUERY
+    SELECT
+        rt.id,
+        rt.title,
+        COUNT(*) FILTER (
+            WHERE r.status_code = 'V/C' -- only consider vacant/clean rooms
+            AND NOT EXISTS (
+                SELECT 1
+                FROM registration reg
+                JOIN reservation res ON res.id = reg.reservation_id
+                WHERE reg.hotel_id = r.hotel_id
+                  AND reg.room_number = r.number
+                  AND res.canceled = FALSE -- ignore canceled reservations
+                  AND res.checkout_date > p_checkin -- overlapping check
+                  AND res.checkin_date < p_checkout
+            )
+        ),
+        rt.base_rate,
+        fn_calculate_payment(rt.id, p_checkin, p_checkout),
+        rt.max_occupancy,
+        rt.bed_count,
+        rt.has_balcony
+    FROM room_type rt
+    JOIN room r
+        ON r.room_type_id = rt.id
+        AND r.hotel_id = p_hotel_id
+    WHERE rt.max_occupancy >= p_guests
+    GROUP BY rt.id
+    ORDER BY rt.base_rate, rt.id;
+END;
+$$ LANGUAGE plpgsql;
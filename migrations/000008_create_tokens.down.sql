@@ -0,0 +1,5 @@
+-- migrations/000008_create_tokens.down.sql
+-- Drops the session tokens table.
+
+DROP INDEX IF EXISTS idx_tokens_employee;
+DROP TABLE IF EXISTS tokens;
@@ -0,0 +1,12 @@
+-- migrations/000008_create_tokens.up.sql
+-- Creates the table for employee session tokens. Only the SHA-256 hash of a token is
+-- stored so that a leaked table cannot be used to authenticate.
+
+CREATE TABLE IF NOT EXISTS tokens (
+    hash BYTEA PRIMARY KEY,
+    employee_id BIGINT NOT NULL REFERENCES employee(id) ON DELETE CASCADE,
+    expiry TIMESTAMP(0) WITH TIME ZONE NOT NULL,
+    scope TEXT NOT NULL
+);
+
+CREATE INDEX idx_tokens_employee ON tokens(employee_id);
@@ -0,0 +1,16 @@
+-- migrations/000009_create_stay_functions.down.sql
+-- Drops the check-in and check-out functions and the check-in tracking column.
+
+DROP FUNCTION IF EXISTS fn_check_out_reservation(
+    BIGINT
+);
+
+DROP FUNCTION IF EXISTS fn_check_in_reservation(
+    BIGINT
+);
+
+DROP FUNCTION IF EXISTS fn_lock_reservation(
+    BIGINT
+);
+
+ALTER TABLE reservation DROP COLUMN IF EXISTS checked_in_at;
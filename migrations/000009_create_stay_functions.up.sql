@@ -0,0 +1,166 @@
+-- migrations/000009_create_stay_functions.up.sql
+-- Adds check-in tracking to reservations and creates the check-in and check-out
+-- workflow functions, which drive room status.
+
+ALTER TABLE reservation ADD COLUMN checked_in_at TIMESTAMP(0) WITH TIME ZONE;
+
+-- open reservations whose rooms are already occupied were checked in before
+-- tracking began, so they are treated as checked in on their check-in date
+UPDATE reservation r
+SET checked_in_at = r.checkin_date
+WHERE r.completed_at IS NULL
+    AND NOT r.canceled
+    AND EXISTS (
+        SELECT 1
+        FROM registration reg
+        JOIN room rm ON rm.hotel_id = reg.hotel_id AND rm.number = reg.room_number
+        WHERE reg.reservation_id = r.id
+            AND rm.status_code IN ('O/C', 'O/D')
+    );
+
+-- ====================================================================================
+-- HELPER FUNCTION fn_lock_reservation locks a reservation row for the rest of the
+-- transaction, raising an exception if it does not exist, was canceled, or was completed.
+-- ====================================================================================
+
+CREATE OR REPLACE FUNCTION fn_lock_reservation(
+    p_reservation_id BIGINT
+)
+RETURNS reservation
+AS $$
+DECLARE
+    v_reservation reservation;
+BEGIN
+    SELECT * INTO v_reservation
+    FROM reservation
+    WHERE id = p_reservation_id
+    FOR UPDATE;
+
+    IF NOT FOUND THEN
+        RAISE EXCEPTION
+            '[reservation-not-found] Reservation % does not exist',
+            p_reservation_id;
+    END IF;
+
+    IF v_reservation.canceled THEN
+        RAISE EXCEPTION
+            '[reservation-canceled] Reservation % has been canceled',
+            p_reservation_id;
+    END IF;
+
+    IF v_reservation.completed_at IS NOT NULL THEN
+        RAISE EXCEPTION
+            '[reservation-completed] Reservation % has already been completed',
+            p_reservation_id;
+    END IF;
+
+    RETURN v_reservation;
+END;
+$$ LANGUAGE plpgsql;
+
+-- ====================================================================================
+-- UPDATE FUNCTION fn_check_in_reservation checks a guest into every room of a reservation
+-- during its stay dates. The rooms must be vacant/clean and become occupied/clean.
+-- ====================================================================================
+
+CREATE OR REPLACE FUNCTION fn_check_in_reservation(
+    p_reservation_id BIGINT
+)
+RETURNS VOID
+AS $$
+DECLARE
+    v_reservation reservation;
+BEGIN
+    v_reservation := fn_lock_reservation(p_reservation_id);
+
+    IF v_reservation.checked_in_at IS NOT NULL THEN
+        RAISE EXCEPTION
+            '[reservation-checked-in] Reservation % has already been checked in',
+            p_reservation_id;
+    END IF;
+
+    -- the last night of the stay is the day before checkout
+    IF CURRENT_DATE < v_reservation.checkin_date
+        OR CURRENT_DATE >= v_reservation.checkout_date THEN
+        RAISE EXCEPTION
+            '[outside-stay-dates] Reservation % can only be checked in from % until the day before %',
+            p_reservation_id, v_reservation.checkin_date, v_reservation.checkout_date;
+    END IF;
+
+    -- lock the rooms and ensure they are ready
+    PERFORM 1
+    FROM room r
+    JOIN registration reg
+        ON reg.hotel_id = r.hotel_id
+        AND reg.room_number = r.number
+    WHERE reg.reservation_id = p_reservation_id
+    FOR UPDATE OF r;
+
+    IF EXISTS (
+        SELECT 1
+        FROM room r
+        JOIN registration reg
+            ON reg.hotel_id = r.hotel_id
+            AND reg.room_number = r.number
+        WHERE reg.reservation_id = p_reservation_id
+            AND r.status_code <> 'V/C'
+    ) THEN
+        RAISE EXCEPTION
+            '[room-not-ready] A room of reservation % is not vacant and clean',
+            p_reservation_id;
+    END IF;
+
+    UPDATE room r
+    SET status_code = 'O/C'
+    FROM registration reg
+    WHERE reg.reservation_id = p_reservation_id
+        AND reg.hotel_id = r.hotel_id
+        AND reg.room_number = r.number;
+
+    UPDATE reservation
+    SET checked_in_at = NOW()
+    WHERE id = p_reservation_id;
+END;
+$$ LANGUAGE plpgsql;
+
+-- ====================================================================================
+-- UPDATE FUNCTION fn_check_out_reservation completes a checked-in reservation. Its rooms
+-- become vacant/dirty and a bed, bathroom, and dusting housekeeping task is created for
+-- each room.
+-- ====================================================================================
+
+CREATE OR REPLACE FUNCTION fn_check_out_reservation(
+    p_reservation_id BIGINT
+)
+RETURNS VOID
+AS $$
+DECLARE
+    v_reservation reservation;
+BEGIN
+    v_reservation := fn_lock_reservation(p_reservation_id);
+
+    IF v_reservation.checked_in_at IS NULL THEN
+        RAISE EXCEPTION
+            '[reservation-not-checked-in] Reservation % has not been checked in',
+            p_reservation_id;
+    END IF;
+
+    UPDATE room r
+    SET status_code = 'V/D'
+    FROM registration reg
+    WHERE reg.reservation_id = p_reservation_id
+        AND reg.hotel_id = r.hotel_id
+        AND reg.room_number = r.number;
+
+    -- unassigned tasks for the housekeeping board
+    INSERT INTO housekeeping_task (hotel_id, room_number, task_type)
+    SELECT reg.hotel_id, reg.room_number, t.task_type
+    FROM registration reg
+    CROSS JOIN unnest(enum_range(NULL::housekeeping_task_type)) AS t(task_type)
+    WHERE reg.reservation_id = p_reservation_id;
+
+    UPDATE reservation
+    SET completed_at = NOW()
+    WHERE id = p_reservation_id;
+END;
+$$ LANGUAGE plpgsql;
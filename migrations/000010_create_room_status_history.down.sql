@@ -0,0 +1,8 @@
+-- migrations/000010_create_room_status_history.down.sql
+-- Drops the room status history trigger and table.
+
+DROP TRIGGER IF EXISTS trg_room_status_history ON room;
+DROP FUNCTION IF EXISTS fn_record_room_status_change();
+
+DROP INDEX IF EXISTS idx_room_status_history_room;
+DROP TABLE IF EXISTS room_status_history;
@@ -0,0 +1,53 @@
+-- migrations/000010_create_room_status_history.up.sql
+-- Creates the room status history table and a trigger that records every change to a
+-- room's status. The employee responsible is read from the hotel.employee_id setting,
+-- which the application sets for the transaction making the change.
+
+CREATE TABLE IF NOT EXISTS room_status_history (
+    id BIGSERIAL PRIMARY KEY,
+    hotel_id INT NOT NULL,
+    room_number INT NOT NULL,
+    employee_id BIGINT REFERENCES employee(id) ON DELETE SET NULL,
+    previous_status room_status NOT NULL,
+    new_status room_status NOT NULL,
+    changed_at TIMESTAMP(0) WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (hotel_id, room_number)
+        REFERENCES room(hotel_id, number)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX idx_room_status_history_room ON room_status_history(hotel_id, room_number, changed_at);
+
+-- ====================================================================================
+-- TRIGGER FUNCTION fn_record_room_status_change inserts a history row when a room's
+-- status changes.
+-- ====================================================================================
+
+CREATE OR REPLACE FUNCTION fn_record_room_status_change()
+RETURNS TRIGGER
+AS $$
+BEGIN
+    INSERT INTO room_status_history (
+        hotel_id,
+        room_number,
+        employee_id,
+        previous_status,
+        new_status
+    )
+    VALUES (
+        NEW.hotel_id,
+        NEW.number,
+        NULLIF(current_setting('hotel.employee_id', TRUE), '')::BIGINT,
+        OLD.status_code,
+        NEW.status_code
+    );
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_room_status_history
+    AFTER UPDATE OF status_code ON room
+    FOR EACH ROW
+    WHEN (OLD.status_code IS DISTINCT FROM NEW.status_code)
+    EXECUTE FUNCTION fn_record_room_status_change();
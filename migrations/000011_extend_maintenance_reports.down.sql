@@ -0,0 +1,181 @@
+-- migrations/000011_extend_maintenance_reports.down.sql
+-- Restores the room allocation, availability, and check-in functions that ignore
+-- maintenance reports, then drops the maintenance report triage columns and types.
+
+-- ====================================================================================
+-- HELPER FUNCTION fn_find_available_room returns the first row that satisfies availability
+-- according to the date range. The lowest room number is selected first.
+-- ====================================================================================
+
+CREATE OR REPLACE FUNCTION fn_find_available_room(
+    p_hotel_id INT,
+    p_room_type_id INT,
+    p_checkin DATE,
+    p_checkout DATE
+)
+RETURNS TABLE (
+    hotel_id INT,
+    room_number INT
+)
+AS $$
+BEGIN
+    RETURN QUERY
+    SELECT r.hotel_id, r.number
+    FROM room r
+    WHERE r.hotel_id = p_hotel_id
+        AND r.room_type_id = p_room_type_id
+        AND r.status_code = 'V/C' -- only consider vacant/clean rooms
+        AND NOT EXISTS (
+            SELECT 1
+            FROM registration reg
+            JOIN reservation res ON res.id = reg.reservation_id
+            WHERE reg.hotel_id = r.hotel_id
+              AND reg.room_number = r.number
+              AND res.canceled = FALSE -- ignore canceled reservations
+              AND res.checkout_date > p_checkin -- overlapping check
+              AND res.checkin_date < p_checkout
+        )
+    ORDER BY r.number
+    FOR UPDATE SKIP LOCKED  -- safe for concurrent allocations
+    LIMIT 1;
+END;
+$$ LANGUAGE plpgsql;
+
+-- ====================================================================================
+-- READ FUNCTION fn_get_availability returns, for every room type in a hotel that can hold
+-- the number of guests, the count of rooms that fn_find_available_room would consider
+-- available for the date range, along with the total price of the stay.
+-- ====================================================================================
+
+CREATE OR REPLACE FUNCTION fn_get_availability(
+    p_hotel_id INT,
+    p_checkin DATE,
+    p_checkout DATE,
+    p_guests INT
+)
+RETURNS TABLE (
+    room_type_id INT,
+    title TEXT,
+    available_rooms BIGINT,
+    base_rate NUMERIC(12, 2),
+    total_price NUMERIC,
+    max_occupancy INT,
+    bed_count INT,
+    has_balcony BOOLEAN
+)
+AS $$
+BEGIN
+    -- handle non-existent hotels
+    PERFORM 1 FROM hotel h WHERE h.id = p_hotel_id;
+
+    IF NOT FOUND THEN
+        RAISE EXCEPTION '[hotel-not-found] Hotel % does not exist', p_hotel_id;
+    END IF;
+
+    RETURN QUERY
+    SELECT
+        rt.id,
+        rt.title,
+        COUNT(*) FILTER (
+            WHERE r.status_code = 'V/C' -- only consider vacant/clean rooms
+            AND NOT EXISTS (
+                SELECT 1
+                FROM registration reg
+                JOIN reservation res ON res.id = reg.reservation_id
+                WHERE reg.hotel_id = r.hotel_id
+                  AND reg.room_number = r.number
+                  AND res.canceled = FALSE -- ignore canceled reservations
+                  AND res.checkout_date > p_checkin -- overlapping check
+                  AND res.checkin_date < p_checkout
+            )
+        ),
+        rt.base_rate,
+        fn_calculate_payment(rt.id, p_checkin, p_checkout),
+        rt.max_occupancy,
+        rt.bed_count,
+        rt.has_balcony
+    FROM room_type rt
+    JOIN room r
+        ON r.room_type_id = rt.id
+        AND r.hotel_id = p_hotel_id
+    WHERE rt.max_occupancy >= p_guests
+    GROUP BY rt.id
+    ORDER BY rt.base_rate, rt.id;
+END;
+$$ LANGUAGE plpgsql;
+
+-- ====================================================================================
+-- UPDATE FUNCTION fn_check_in_reservation checks a guest into every room of a reservation
+-- during its stay dates. The rooms must be vacant/clean and become occupied/clean.
+-- ====================================================================================
+
+CREATE OR REPLACE FUNCTION fn_check_in_reservation(
+    p_reservation_id BIGINT
+)
+RETURNS VOID
+AS $$
+DECLARE
+    v_reservation reservation;
+BEGIN
+    v_reservation := fn_lock_reservation(p_reservation_id);
+
+    IF v_reservation.checked_in_at IS NOT NULL THEN
+        RAISE EXCEPTION
+            '[reservation-checked-in] Reservation % has already been checked in',
+            p_reservation_id;
+    END IF;
+
+    -- the last night of the stay is the day before checkout
+    IF CURRENT_DATE < v_reservation.checkin_date
+        OR CURRENT_DATE >= v_reservation.checkout_date THEN
+        RAISE EXCEPTION
+            '[outside-stay-dates] Reservation % can only be checked in from % until the day before %',
+            p_reservation_id, v_reservation.checkin_date, v_reservation.checkout_date;
+    END IF;
+
+    -- lock the rooms and ensure they are ready
+    PERFORM 1
+    FROM room r
+    JOIN registration reg
+        ON reg.hotel_id = r.hotel_id
+        AND reg.room_number = r.number
+    WHERE reg.reservation_id = p_reservation_id
+    FOR UPDATE OF r;
+
+    IF EXISTS (
+        SELECT 1
+        FROM room r
+        JOIN registration reg
+            ON reg.hotel_id = r.hotel_id
+            AND reg.room_number = r.number
+        WHERE reg.reservation_id = p_reservation_id
+            AND r.status_code <> 'V/C'
+    ) THEN
+        RAISE EXCEPTION
+            '[room-not-ready] A room of reservation % is not vacant and clean',
+            p_reservation_id;
+    END IF;
+
+    UPDATE room r
+    SET status_code = 'O/C'
+    FROM registration reg
+    WHERE reg.reservation_id = p_reservation_id
+        AND reg.hotel_id = r.hotel_id
+        AND reg.room_number = r.number;
+
+    UPDATE reservation
+    SET checked_in_at = NOW()
+    WHERE id = p_reservation_id;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP INDEX IF EXISTS idx_maintenance_out_of_order;
+
+ALTER TABLE maintenance_report
+    DROP COLUMN IF EXISTS out_of_order,
+    DROP COLUMN IF EXISTS technician_id,
+    DROP COLUMN IF EXISTS category,
+    DROP COLUMN IF EXISTS priority;
+
+DROP TYPE IF EXISTS maintenance_category;
+DROP TYPE IF EXISTS maintenance_priority;
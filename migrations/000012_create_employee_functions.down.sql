@@ -0,0 +1,21 @@
+-- migrations/000012_create_employee_functions.down.sql
+-- Drops the employee creation function.
+
+DROP FUNCTION IF EXISTS fn_create_employee(
+    BIGINT,
+    TEXT,
+    BIGINT,
+    NUMERIC,
+    TEXT,
+    CITEXT,
+    TEXT,
+    BYTEA,
+    TEXT,
+    TEXT,
+    BOOLEAN,
+    TEXT,
+    TEXT,
+    TEXT,
+    TEXT,
+    TEXT
+);
@@ -0,0 +1,92 @@
+-- migrations/000012_create_employee_functions.up.sql
+-- Creates the function that creates an employee along with their person and subtype
+-- rows in one statement.
+
+-- ====================================================================================
+-- CREATE FUNCTION fn_create_employee returns the person id and created_at for a newly
+-- created employee from the passed employee, subtype, and person details. The role
+-- decides which subtype table the employee is added to: operations managers use the
+-- hotel owner flag, while front desk employees and housekeepers use the shift.
+-- ====================================================================================
+
+CREATE OR REPLACE FUNCTION fn_create_employee(
+    -- employee attributes
+    p_hotel_id BIGINT,
+    p_department TEXT,
+    p_reports_to BIGINT,
+    p_salary NUMERIC(18, 2),
+    p_ssn TEXT,
+    p_work_email CITEXT,
+    p_work_phone TEXT,
+    p_password_hash BYTEA,
+    -- subtype attributes
+    p_role TEXT,
+    p_shift TEXT,
+    p_hotel_owner BOOLEAN,
+    -- person attributes
+    p_name TEXT,
+    p_gender TEXT,
+    p_street TEXT,
+    p_city TEXT,
+    p_country TEXT
+)
+RETURNS TABLE (
+    id BIGINT,
+    created_at TIMESTAMP(0) WITH TIME ZONE
+)
+AS $$
+DECLARE
+    v_employee_id BIGINT;
+BEGIN
+    -- insert person entry
+    INSERT INTO person (name, gender, street, city, country)
+    VALUES (p_name, p_gender, p_street, p_city, p_country)
+    RETURNING person.id INTO v_employee_id;
+
+    -- insert employee entry
+    INSERT INTO employee (
+        id,
+        hotel_id,
+        department,
+        reports_to,
+        salary,
+        ssn,
+        work_email,
+        work_phone,
+        password_hash
+    )
+    VALUES (
+        v_employee_id,
+        p_hotel_id,
+        p_department,
+        p_reports_to,
+        p_salary,
+        p_ssn,
+        p_work_email,
+        p_work_phone,
+        p_password_hash
+    );
+
+    -- insert subtype entry
+    CASE p_role
+        WHEN 'operations_manager' THEN
+            INSERT INTO operations_manager (id, hotel_owner)
+            VALUES (v_employee_id, p_hotel_owner);
+        WHEN 'front_desk' THEN
+            INSERT INTO front_desk (id, shift)
+            VALUES (v_employee_id, p_shift::shift_type);
+        WHEN 'housekeeper' THEN
+            INSERT INTO housekeeper (id, shift)
+            VALUES (v_employee_id, p_shift::shift_type);
+        ELSE
+            RAISE EXCEPTION '[invalid-employee-role] Role % does not exist', p_role;
+    END CASE;
+
+    RETURN QUERY
+    SELECT
+        p.id,
+        p.created_at
+    FROM person p
+    WHERE p.id = v_employee_id;
+END;
+$$ LANGUAGE plpgsql;
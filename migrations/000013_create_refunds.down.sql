@@ -0,0 +1,11 @@
+-- migrations/000013_create_refunds.down.sql
+-- Drops the reservation cancellation function and the refund table.
+
+DROP FUNCTION IF EXISTS fn_cancel_reservation(
+    BIGINT,
+    BIGINT,
+    INT,
+    NUMERIC
+);
+
+DROP TABLE IF EXISTS refund;
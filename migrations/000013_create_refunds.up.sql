@@ -0,0 +1,82 @@
+-- migrations/000013_create_refunds.up.sql
+-- Creates the refund table and the reservation cancellation function, which records the
+-- refund owed under the refund policy passed by the application.
+
+CREATE TABLE IF NOT EXISTS refund (
+    id BIGSERIAL PRIMARY KEY,
+    reservation_id BIGINT UNIQUE NOT NULL REFERENCES reservation(id) ON DELETE CASCADE,
+    employee_id BIGINT REFERENCES employee(id) ON DELETE SET NULL,
+    amount NUMERIC(12, 2) NOT NULL CHECK (amount >= 0),
+    percentage NUMERIC(5, 2) NOT NULL CHECK (percentage BETWEEN 0 AND 100),
+    created_at TIMESTAMP(0) WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- ====================================================================================
+-- UPDATE FUNCTION fn_cancel_reservation cancels a reservation that has not been checked
+-- in before its checkout date and returns the refund recorded for it. The full payment is refunded when canceling
+-- at least p_free_days days before the check-in date; otherwise p_late_percentage percent
+-- of the payment is refunded.
+-- ====================================================================================
+
+CREATE OR REPLACE FUNCTION fn_cancel_reservation(
+    p_reservation_id BIGINT,
+    p_employee_id BIGINT,
+    p_free_days INT,
+    p_late_percentage NUMERIC
+)
+RETURNS TABLE (
+    id BIGINT,
+    reservation_id BIGINT,
+    employee_id BIGINT,
+    amount NUMERIC(12, 2),
+    percentage NUMERIC(5, 2),
+    created_at TIMESTAMP(0) WITH TIME ZONE
+)
+AS $$
+DECLARE
+    v_reservation reservation;
+    v_percentage NUMERIC(5, 2);
+BEGIN
+    v_reservation := fn_lock_reservation(p_reservation_id);
+
+    IF v_reservation.checked_in_at IS NOT NULL THEN
+        RAISE EXCEPTION
+            '[reservation-checked-in] Reservation % has already been checked in',
+            p_reservation_id;
+    END IF;
+
+    -- a stay that ended without a check-in was a no-show, not a cancellation
+    IF CURRENT_DATE >= v_reservation.checkout_date THEN
+        RAISE EXCEPTION
+            '[reservation-no-show] Reservation % ended on % without being checked in',
+            p_reservation_id, v_reservation.checkout_date;
+    END IF;
+
+    IF CURRENT_DATE <= v_reservation.checkin_date - p_free_days THEN
+        v_percentage := 100;
+    ELSE
+        v_percentage := p_late_percentage;
+    END IF;
+
+    -- canceled reservations no longer hold their rooms
+    UPDATE reservation
+    SET canceled = TRUE
+    WHERE reservation.id = p_reservation_id;
+
+    RETURN QUERY
+    INSERT INTO refund (reservation_id, employee_id, amount, percentage)
+    VALUES (
+        p_reservation_id,
+        p_employee_id,
+        ROUND(v_reservation.payment_amount * v_percentage / 100, 2),
+        v_percentage
+    )
+    RETURNING
+        refund.id,
+        refund.reservation_id,
+        refund.employee_id,
+        refund.amount,
+        refund.percentage,
+        refund.created_at;
+END;
+$$ LANGUAGE plpgsql;
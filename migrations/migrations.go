@@ -0,0 +1,11 @@
+// Package migrations embeds the SQL migration files so that they can be applied
+// by the api binary.
+package migrations
+
+import "embed"
+
+// Files holds the up and down migrations, named in the golang-migrate format
+// <version>_<name>.<up|down>.sql.
+//
+//go:embed *.sql
+var Files embed.FS